@@ -1,4 +1,6 @@
 highlight delveBreakpointSign  guifg=#cc1100  guibg=None
+highlight delveBreakpointDisabledSign guifg=#7a7a7a  guibg=None
+highlight delveBreakpointConditionalSign guifg=#d08020  guibg=None
 highlight delveTracepointSign  guifg=#5398d0  guibg=None
 
 highlight delvePCSign          guifg=#bbbb00  guibg=None
@@ -49,6 +49,7 @@ nnoremap <silent><Plug>(nvim-go-vet)  :<C-u>Govet<CR>
 nnoremap <silent><Plug>(nvim-go-delve-debug)    :<C-u>DlvDebug<CR>
 nnoremap <silent><Plug>(nvim-go-delve-exec)     :<C-u>DlvExec<CR>
 nnoremap <silent><Plug>(nvim-go-delve-connect)  :<C-u>DlvConnct<CR>
+nnoremap <silent><Plug>(nvim-go-delve-reconnect)  :<C-u>DlvReconnect<CR>
 
 " Set (Break|Trace)point
 nnoremap <silent><Plug>(nvim-go-delve-breakpoint)  :<C-u>DlvBreakpoint<CR>
@@ -56,12 +57,17 @@ nnoremap <silent><Plug>(nvim-go-delve-tracepoint)  :<C-u>DlvTracepoint<CR>
 
 " Stepping execution (program counter)
 nnoremap <silent><Plug>(nvim-go-delve-continue)         :<C-u>DlvContinue<CR>
+nnoremap <silent><Plug>(nvim-go-delve-halt)             :<C-u>DlvHalt<CR>
 nnoremap <silent><Plug>(nvim-go-delve-next)             :<C-u>DlvNext<CR>
 nnoremap <silent><Plug>(nvim-go-delve-step)             :<C-u>DlvStep<CR>
 nnoremap <silent><Plug>(nvim-go-delve-stepinstruction)  :<C-u>DlvStepInstruction<CR>
 nnoremap <silent><Plug>(nvim-go-delve-restart)          :<C-u>DlvRestart<CR>
 nnoremap <silent><Plug>(nvim-go-delve-stop)             :<C-u>DlvStop<CR>
 
+" Evaluate the word under the cursor or the visual selection
+nnoremap <silent><Plug>(nvim-go-delve-eval)  :<C-u>DlvEval<CR>
+xnoremap <silent><Plug>(nvim-go-delve-eval)  :DlvEval<CR>
+
 " Interactive mode
 nnoremap <silent><Plug>(nvim-go-delve-stdin)  :<C-u>DlvStdin<CR>
 
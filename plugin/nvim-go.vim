@@ -81,6 +81,18 @@ let g:go#terminal#stop_insert = get(g:, 'go#terminal#stop_insert', 1)
 let g:go#test#all_package = get(g:, 'go#test#all_package', 0)
 let g:go#test#autosave    = get(g:, 'go#test#autosave', 0)
 let g:go#test#flags       = get(g:, 'go#test#flags', [])
+let g:go#test#mode        = get(g:, 'go#test#mode', 'terminal')
+
+" GoWatch
+let g:go#watch#build    = get(g:, 'go#watch#build', 1)
+let g:go#watch#vet      = get(g:, 'go#watch#vet', 0)
+let g:go#watch#test     = get(g:, 'go#watch#test', 0)
+let g:go#watch#debounce = get(g:, 'go#watch#debounce', 500)
+
+" Delve
+let g:go#delve#timeout = get(g:, 'go#delve#timeout', 30000)
+" status of the debugging session, which is set by nvim-go. 'running' or 'stopped'
+let g:go#delve#status = get(g:, 'go#delve#status', '')
 
 " Debugging
 let g:go#debug       = get(g:, 'go#debug', 0)
@@ -115,25 +127,54 @@ endfunction
 " plugin manifest
 call remote#host#Register(s:plugin_name, '*', function('s:RequireNvimGo'))
 call remote#host#RegisterPlugin('nvim-go', '0', [
+\ {'type': 'autocmd', 'name': 'BufReadPost', 'sync': 0, 'opts': {'eval': 'expand(''<afile>:p'')', 'group': 'nvim-go', 'pattern': '*.go'}},
 \ {'type': 'autocmd', 'name': 'BufWritePost', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]', 'group': 'nvim-go', 'pattern': '*.go'}},
 \ {'type': 'autocmd', 'name': 'BufWritePre', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]', 'group': 'nvim-go', 'pattern': '*.go'}},
-\ {'type': 'autocmd', 'name': 'VimEnter', 'sync': 0, 'opts': {'eval': '{''Global'': {''ServerName'': v:servername, ''ErrorListType'': g:go#global#errorlisttype}, ''Analyze'': {''FoldIcon'': g:go#analyze#foldicon}, ''Build'': {''Autosave'': g:go#build#autosave, ''Force'': g:go#build#force, ''Flags'': g:go#build#flags}, ''Fmt'': {''Autosave'': g:go#fmt#autosave, ''Mode'': g:go#fmt#mode}, ''Generate'': {''TestAllFuncs'': g:go#generate#test#allfuncs, ''TestExclFuncs'': g:go#generate#test#exclude, ''TestExportedFuncs'': g:go#generate#test#exportedfuncs, ''TestSubTest'': g:go#generate#test#subtest}, ''Guru'': {''Reflection'': g:go#guru#reflection, ''KeepCursor'': g:go#guru#keep_cursor, ''JumpFirst'': g:go#guru#jump_first}, ''Iferr'': {''Autosave'': g:go#iferr#autosave}, ''Lint'': {''GolintIgnore'': g:go#lint#golint#ignore, ''GolintMinConfidence'': g:go#lint#golint#min_confidence, ''GolintMode'': g:go#lint#golint#mode, ''GoVetAutosave'': g:go#lint#govet#autosave, ''GoVetFlags'': g:go#lint#govet#flags, ''MetalinterAutosave'': g:go#lint#metalinter#autosave, ''MetalinterAutosaveTools'': g:go#lint#metalinter#autosave#tools, ''MetalinterTools'': g:go#lint#metalinter#tools, ''MetalinterDeadline'': g:go#lint#metalinter#deadline, ''MetalinterSkipDir'': g:go#lint#metalinter#skip_dir}, ''Rename'': {''Prefill'': g:go#rename#prefill}, ''Terminal'': {''Mode'': g:go#terminal#mode, ''Position'': g:go#terminal#position, ''Height'': g:go#terminal#height, ''Width'': g:go#terminal#width, ''StopInsert'': g:go#terminal#stop_insert}, ''Test'': {''AllPackage'': g:go#test#all_package, ''Autosave'': g:go#test#autosave, ''Flags'': g:go#test#flags}, ''Debug'': {''Enable'': g:go#debug, ''Pprof'': g:go#debug#pprof}}', 'group': 'nvim-go', 'pattern': '*.go'}},
-\ {'type': 'autocmd', 'name': 'VimLeavePre', 'sync': 0, 'opts': {'group': 'nvim-go', 'pattern': '*.go,terminal,context,thread'}},
-\ {'type': 'command', 'name': 'DlvBreakpoint', 'sync': 0, 'opts': {'complete': 'customlist,FunctionsCompletion', 'eval': '[expand(''%:p'')]', 'nargs': '*'}},
+\ {'type': 'autocmd', 'name': 'VimEnter', 'sync': 0, 'opts': {'eval': '{''Global'': {''ServerName'': v:servername, ''ErrorListType'': g:go#global#errorlisttype}, ''Analyze'': {''FoldIcon'': g:go#analyze#foldicon}, ''Build'': {''Autosave'': g:go#build#autosave, ''Force'': g:go#build#force, ''Flags'': g:go#build#flags}, ''Delve'': {''Timeout'': g:go#delve#timeout}, ''Fmt'': {''Autosave'': g:go#fmt#autosave, ''Mode'': g:go#fmt#mode}, ''Generate'': {''TestAllFuncs'': g:go#generate#test#allfuncs, ''TestExclFuncs'': g:go#generate#test#exclude, ''TestExportedFuncs'': g:go#generate#test#exportedfuncs, ''TestSubTest'': g:go#generate#test#subtest}, ''Guru'': {''Reflection'': g:go#guru#reflection, ''KeepCursor'': g:go#guru#keep_cursor, ''JumpFirst'': g:go#guru#jump_first}, ''Iferr'': {''Autosave'': g:go#iferr#autosave}, ''Lint'': {''GolintIgnore'': g:go#lint#golint#ignore, ''GolintMinConfidence'': g:go#lint#golint#min_confidence, ''GolintMode'': g:go#lint#golint#mode, ''GoVetAutosave'': g:go#lint#govet#autosave, ''GoVetFlags'': g:go#lint#govet#flags, ''MetalinterAutosave'': g:go#lint#metalinter#autosave, ''MetalinterAutosaveTools'': g:go#lint#metalinter#autosave#tools, ''MetalinterTools'': g:go#lint#metalinter#tools, ''MetalinterDeadline'': g:go#lint#metalinter#deadline, ''MetalinterSkipDir'': g:go#lint#metalinter#skip_dir}, ''Rename'': {''Prefill'': g:go#rename#prefill}, ''Terminal'': {''Mode'': g:go#terminal#mode, ''Position'': g:go#terminal#position, ''Height'': g:go#terminal#height, ''Width'': g:go#terminal#width, ''StopInsert'': g:go#terminal#stop_insert}, ''Test'': {''AllPackage'': g:go#test#all_package, ''Autosave'': g:go#test#autosave, ''Flags'': g:go#test#flags, ''Mode'': g:go#test#mode}, ''Watch'': {''Build'': g:go#watch#build, ''Vet'': g:go#watch#vet, ''Test'': g:go#watch#test, ''Debounce'': g:go#watch#debounce}, ''Debug'': {''Enable'': g:go#debug, ''Pprof'': g:go#debug#pprof}}', 'group': 'nvim-go', 'pattern': '*.go'}},
+\ {'type': 'autocmd', 'name': 'VimLeavePre', 'sync': 0, 'opts': {'group': 'nvim-go', 'pattern': '*.go,terminal,context,thread,disassembly,watch,output'}},
+\ {'type': 'command', 'name': 'DlvAttach', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '+'}},
+\ {'type': 'command', 'name': 'DlvBreakpoint', 'sync': 0, 'opts': {'complete': 'customlist,FunctionsCompletion', 'eval': '[expand(''%:p''), line(''.'')]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'DlvBreakpoints', 'sync': 0, 'opts': {'eval': 'get(b:, ''dlv_breakpoints_dir'', expand(''%:p:h''))', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'DlvConnect', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'DlvContinue', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'DlvCore', 'sync': 0, 'opts': {'complete': 'file', 'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '+'}},
 \ {'type': 'command', 'name': 'DlvDebug', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '*'}},
-\ {'type': 'command', 'name': 'DlvDetach', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'DlvDetach', 'sync': 0, 'opts': {'bang': ''}},
+\ {'type': 'command', 'name': 'DlvEval', 'sync': 0, 'opts': {'count': '0', 'eval': '[expand(''<cword>''), getpos("''<"), getpos("''>")]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'DlvExec', 'sync': 0, 'opts': {'complete': 'file', 'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '+'}},
+\ {'type': 'command', 'name': 'DlvHalt', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'DlvNext', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]'}},
+\ {'type': 'command', 'name': 'DlvReconnect', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]'}},
 \ {'type': 'command', 'name': 'DlvRestart', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'DlvRunToCursor', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h''), expand(''%:p''), line(''.'')]'}},
 \ {'type': 'command', 'name': 'DlvState', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'DlvStdin', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'DlvStep', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]'}},
+\ {'type': 'command', 'name': 'DlvStepInstruction', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]'}},
+\ {'type': 'command', 'name': 'DlvStepOut', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]'}},
+\ {'type': 'command', 'name': 'DlvTest', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h''), expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'DlvTrace', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '+'}},
+\ {'type': 'command', 'name': 'DlvUnwatch', 'sync': 0, 'opts': {'complete': 'customlist,DlvWatchCompletion', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'DlvWatch', 'sync': 0, 'opts': {'nargs': '+'}},
+\ {'type': 'command', 'name': 'GoBench', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoBenchBaseline', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'GoBenchSave', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '1'}},
 \ {'type': 'command', 'name': 'GoBuffers', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'GoBuildCache', 'sync': 1, 'opts': {}},
 \ {'type': 'command', 'name': 'GoByteOffset', 'sync': 1, 'opts': {'eval': 'expand(''%:p'')', 'range': '%'}},
+\ {'type': 'command', 'name': 'GoCoverage', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoCoverageClear', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoCoverageToggle', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]'}},
 \ {'type': 'command', 'name': 'GoGenerateTest', 'sync': 0, 'opts': {'addr': 'line', 'bang': '', 'complete': 'file', 'eval': 'expand(''%:p:h'')', 'nargs': '*', 'range': '%'}},
 \ {'type': 'command', 'name': 'GoIferr', 'sync': 0, 'opts': {'eval': 'expand(''%:p'')'}},
+\ {'type': 'command', 'name': 'GoInstall', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p'')]'}},
 \ {'type': 'command', 'name': 'GoSwitchTest', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]'}},
 \ {'type': 'command', 'name': 'GoTabpages', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'GoTestFunc', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoTestHistory', 'sync': 0, 'opts': {'eval': 'get(b:, ''go_test_history_dir'', expand(''%:p:h''))', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'GoTestLast', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')'}},
+\ {'type': 'command', 'name': 'GoWatch', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoWatchStop', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p'')]'}},
 \ {'type': 'command', 'name': 'GoWindows', 'sync': 1, 'opts': {}},
 \ {'type': 'command', 'name': 'Gobuild', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p'')]'}},
 \ {'type': 'command', 'name': 'Gofmt', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')'}},
@@ -144,6 +185,8 @@ call remote#host#RegisterPlugin('nvim-go', '0', [
 \ {'type': 'command', 'name': 'GorunLast', 'sync': 0, 'opts': {'eval': 'expand(''%:p'')'}},
 \ {'type': 'command', 'name': 'Gotest', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'Govet', 'sync': 0, 'opts': {'complete': 'customlist,GoVetCompletion', 'eval': '[getcwd(), expand(''%:p'')]', 'nargs': '*'}},
+\ {'type': 'function', 'name': 'DlvStdinCompletion', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'DlvWatchCompletion', 'sync': 1, 'opts': {}},
 \ {'type': 'function', 'name': 'FunctionsCompletion', 'sync': 1, 'opts': {}},
 \ {'type': 'function', 'name': 'GoGuru', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2)]'}},
 \ {'type': 'function', 'name': 'GoLintCompletion', 'sync': 1, 'opts': {'eval': 'getcwd()'}},
@@ -38,9 +38,8 @@ func (a *Autocmd) bufWritePost(eval *bufWritePostEval) error {
 			}
 		case []*nvim.QuickfixError:
 			// Cleanup Errlist
-			a.ctxt.Errlist = make(map[string][]*nvim.QuickfixError)
-			a.ctxt.Errlist["Fmt"] = e
-			return nvimutil.ErrorList(a.Nvim, a.ctxt.Errlist, true)
+			a.ctxt.ResetErrlist("Fmt", e)
+			return nvimutil.ErrorList(a.Nvim, a.ctxt.CopyErrlist(), true)
 		}
 	}
 
@@ -58,11 +57,10 @@ func (a *Autocmd) bufWritePost(eval *bufWritePostEval) error {
 			}
 		case []*nvim.QuickfixError:
 			// Cleanup Errlist
-			a.ctxt.Errlist = make(map[string][]*nvim.QuickfixError)
-			a.ctxt.Errlist["Build"] = e
-			return nvimutil.ErrorList(a.Nvim, a.ctxt.Errlist, true)
+			a.ctxt.ResetErrlist("Build", e)
+			return nvimutil.ErrorList(a.Nvim, a.ctxt.CopyErrlist(), true)
 		}
-		if len(a.ctxt.Errlist) == 0 {
+		if len(a.ctxt.CopyErrlist()) == 0 {
 			nvimutil.CloseLoclist(a.Nvim)
 		}
 	}
@@ -70,7 +68,7 @@ func (a *Autocmd) bufWritePost(eval *bufWritePostEval) error {
 	if config.GoVetAutosave {
 		go func() {
 			// Cleanup old results
-			a.ctxt.Errlist["Vet"] = nil
+			a.ctxt.SetErrlist("Vet", nil)
 
 			errlist, err := a.cmds.Vet(nil, &commands.CmdVetEval{
 				Cwd:  eval.Cwd,
@@ -82,13 +80,13 @@ func (a *Autocmd) bufWritePost(eval *bufWritePostEval) error {
 				return
 			}
 			if errlist != nil {
-				a.ctxt.Errlist["Vet"] = errlist
-				if len(a.ctxt.Errlist) > 0 {
-					nvimutil.ErrorList(a.Nvim, a.ctxt.Errlist, true)
+				a.ctxt.SetErrlist("Vet", errlist)
+				if errs := a.ctxt.CopyErrlist(); len(errs) > 0 {
+					nvimutil.ErrorList(a.Nvim, errs, true)
 					return
 				}
 			}
-			if a.ctxt.Errlist["Vet"] == nil {
+			if a.ctxt.CopyErrlist()["Vet"] == nil {
 				nvimutil.ClearErrorlist(a.Nvim, true)
 			}
 		}()
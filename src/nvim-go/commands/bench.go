@@ -0,0 +1,485 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/context"
+	"nvim-go/internal/storage"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/pkg/errors"
+)
+
+const benchBufName = "__GO_BENCH__"
+
+var (
+	// benchMu Mutex lock for benchLast, benchBaseline and benchBuf.
+	benchMu sync.Mutex
+	// benchLast last benchmark run keyed by project root.
+	benchLast = make(map[string]*benchRun)
+	// benchBaseline selected baseline name keyed by project root.
+	benchBaseline = make(map[string]string)
+	// benchBuf benchmark table buffer of the GoBench.
+	benchBuf *nvimutil.Buffer
+)
+
+// benchResult represents a benchmark result, which has the samples per
+// "-count" of the each metrics.
+type benchResult struct {
+	Name        string    `json:"name"`
+	NsPerOp     []float64 `json:"ns_per_op"`
+	BytesPerOp  []float64 `json:"bytes_per_op,omitempty"`
+	AllocsPerOp []float64 `json:"allocs_per_op,omitempty"`
+}
+
+// benchRun represents a benchmark run of the package.
+type benchRun struct {
+	Package    string         `json:"package"`
+	Time       time.Time      `json:"time"`
+	Benchmarks []*benchResult `json:"benchmarks"`
+}
+
+// find returns the name benchmark result, or nil if not found.
+func (r *benchRun) find(name string) *benchResult {
+	for _, b := range r.Benchmarks {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// benchLineRe matches the benchmark result line such as "BenchmarkFoo-8   1000   1234 ns/op".
+var benchLineRe = regexp.MustCompile(`^(Benchmark\S*)\s+\d+\s+(.*)$`)
+
+// parseBench parses the benchmark result lines of the go test output.
+// The multiple results of the same benchmark are merged as the samples.
+func parseBench(r io.Reader) ([]*benchResult, error) {
+	var (
+		results []*benchResult
+		index   = make(map[string]*benchResult)
+	)
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		m := benchLineRe.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		b, ok := index[m[1]]
+		if !ok {
+			b = &benchResult{Name: m[1]}
+			index[m[1]] = b
+			results = append(results, b)
+		}
+
+		fields := strings.Fields(m[2])
+		for i := 0; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				b.NsPerOp = append(b.NsPerOp, v)
+			case "B/op":
+				b.BytesPerOp = append(b.BytesPerOp, v)
+			case "allocs/op":
+				b.AllocsPerOp = append(b.AllocsPerOp, v)
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return results, nil
+}
+
+// benchStat represents the statistics of the benchmark metric samples.
+type benchStat struct {
+	Mean   float64
+	Stddev float64
+	N      int
+}
+
+// newBenchStat returns the statistics of samples.
+func newBenchStat(samples []float64) benchStat {
+	st := benchStat{N: len(samples)}
+	if st.N == 0 {
+		return st
+	}
+	for _, v := range samples {
+		st.Mean += v
+	}
+	st.Mean /= float64(st.N)
+	if st.N > 1 {
+		var sum float64
+		for _, v := range samples {
+			sum += (v - st.Mean) * (v - st.Mean)
+		}
+		st.Stddev = math.Sqrt(sum / float64(st.N-1))
+	}
+
+	return st
+}
+
+// tCritical95 two-tailed critical values of the Student's t-distribution at
+// the 95% confidence level for 1-30 degrees of freedom.
+var tCritical95 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// welchSignificant reports whether the difference of the means of a and b is
+// significant at the 95% confidence level by the Welch's t-test.
+// ok is false if either samples are less than 2, which could not estimate.
+func welchSignificant(a, b benchStat) (significant, ok bool) {
+	if a.N < 2 || b.N < 2 {
+		return false, false
+	}
+
+	va, vb := a.Stddev*a.Stddev/float64(a.N), b.Stddev*b.Stddev/float64(b.N)
+	se2 := va + vb
+	if se2 == 0 {
+		return a.Mean != b.Mean, true
+	}
+	t := math.Abs(a.Mean-b.Mean) / math.Sqrt(se2)
+	df := se2 * se2 / (va*va/float64(a.N-1) + vb*vb/float64(b.N-1))
+
+	critical := 1.96
+	switch d := int(df); {
+	case d < 1:
+		critical = tCritical95[0]
+	case d <= len(tCritical95):
+		critical = tCritical95[d-1]
+	case d <= 120:
+		critical = 2.0
+	}
+
+	return t > critical, true
+}
+
+// benchDelta returns the formatted percentage delta from old to new.
+func benchDelta(old, new benchStat) string {
+	switch {
+	case old.N == 0 || new.N == 0:
+		return "-"
+	case old.Mean == 0 && new.Mean == 0:
+		return "+0.00%"
+	case old.Mean == 0:
+		return "+Inf%"
+	}
+	return fmt.Sprintf("%+.2f%%", (new.Mean-old.Mean)/old.Mean*100)
+}
+
+// benchTable returns the table lines of run, and the deltas from the baseline
+// if baseline is not nil.
+func benchTable(run, baseline *benchRun, baselineName string) [][]byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "pkg: %s  time: %s", run.Package, run.Time.Format("2006-01-02 15:04:05"))
+	if baseline != nil {
+		fmt.Fprintf(&buf, "  baseline: %s (%s)", baselineName, baseline.Time.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprint(&buf, "\n\n")
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprint(w, "name\tns/op\tB/op\tallocs/op")
+	if baseline != nil {
+		fmt.Fprint(w, "\tdelta ns/op\tdelta B/op\tdelta allocs/op")
+	}
+	fmt.Fprintln(w)
+
+	for _, b := range run.Benchmarks {
+		ns, mem, allocs := newBenchStat(b.NsPerOp), newBenchStat(b.BytesPerOp), newBenchStat(b.AllocsPerOp)
+
+		nsCol := fmt.Sprintf("%.2f", ns.Mean)
+		if ns.N > 1 && ns.Mean != 0 {
+			nsCol += fmt.Sprintf(" ±%.0f%%", ns.Stddev/ns.Mean*100)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s", b.Name, nsCol, benchMean(mem), benchMean(allocs))
+
+		if baseline != nil {
+			old := baseline.find(b.Name)
+			if old == nil {
+				fmt.Fprint(w, "\t-\t-\t-")
+			} else {
+				oldNs := newBenchStat(old.NsPerOp)
+				mark := "(?)"
+				if significant, ok := welchSignificant(oldNs, ns); ok {
+					mark = "(~)"
+					if significant {
+						mark = "(*)"
+					}
+				}
+				fmt.Fprintf(w, "\t%s %s\t%s\t%s", benchDelta(oldNs, ns), mark,
+					benchDelta(newBenchStat(old.BytesPerOp), mem), benchDelta(newBenchStat(old.AllocsPerOp), allocs))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+
+	if baseline != nil {
+		fmt.Fprint(&buf, "\n(*) significant at p<0.05, (~) not significant, (?) re-run with -count to estimate\n")
+	}
+
+	return nvimutil.ToBufferLines(bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}))
+}
+
+// benchMean returns the formatted mean of st, or "-" if no samples.
+func benchMean(st benchStat) string {
+	if st.N == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", st.Mean)
+}
+
+// benchBaselineFile returns the baseline file path of the project.
+func benchBaselineFile(project, name string) string {
+	return storage.DataPath("bench", project, name+".json")
+}
+
+// loadBenchBaseline loads the name baseline of the project.
+func loadBenchBaseline(project, name string) (*benchRun, error) {
+	data, err := ioutil.ReadFile(benchBaselineFile(project, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("not found the %s baseline", name)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var run benchRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &run, nil
+}
+
+// validBaselineName reports whether the name can be used as a file name.
+func validBaselineName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// ----------------------------------------------------------------------------
+// GoBench
+
+func (c *Commands) cmdBench(args []string, dir string) {
+	go c.Bench(args, dir)
+}
+
+// Bench runs the benchmarks of the current buffer's package, and renders the
+// result table with the deltas from the selected baseline.
+// The first args is the -bench pattern unless starts with "-", the rest of
+// args is passed to the go test command, e.g. "-count 5".
+func (c *Commands) Bench(args []string, dir string) error {
+	defer nvimutil.Profile(time.Now(), "GoBench")
+	bctxt := context.NewBuildContext(dir)
+
+	if bctxt.Build.Tool == "gb" {
+		return nvimutil.ErrorWrap(c.Nvim, errors.New("GoBench does not support gb"))
+	}
+
+	pattern := "."
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		pattern, args = args[0], args[1:]
+	}
+
+	pkgID, err := pathutil.PackageIDContext(&bctxt.Context, dir)
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+
+	cmd := exec.Command(bctxt.Build.Command(), "test", "-run", "^$", "-bench", pattern, "-benchmem")
+	cmd.Args = append(cmd.Args, config.TestFlags...)
+	cmd.Args = append(cmd.Args, args...)
+	cmd.Args = append(cmd.Args, pkgID)
+	cmd.Dir = testWorkDir(bctxt, dir)
+	cmd.Env = bctxt.Env
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	nvimutil.EchoProgress(c.Nvim, "GoBench", "benchmarking %s", pkgID)
+	if benchErr := cmd.Run(); benchErr != nil {
+		if _, ok := benchErr.(*exec.ExitError); !ok {
+			return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(benchErr))
+		}
+		errlist, err := nvimutil.ParseError(out.Bytes(), cmd.Dir, bctxt)
+		if err != nil {
+			return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+		}
+		if len(errlist) == 0 {
+			return nvimutil.ErrorWrap(c.Nvim, errors.New(string(bytes.TrimSpace(out.Bytes()))))
+		}
+		c.ctx.SetErrlist("Bench", errlist)
+		return nvimutil.ErrorList(c.Nvim, c.ctx.CopyErrlist(), true)
+	}
+	c.ctx.DeleteErrlist("Bench")
+
+	results, err := parseBench(&out)
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, err)
+	}
+	if len(results) == 0 {
+		return nvimutil.EchohlErr(c.Nvim, "GoBench", fmt.Sprintf("no benchmarks matched %s", pattern))
+	}
+
+	project := storage.ProjectRoot(bctxt, dir)
+	run := &benchRun{Package: pkgID, Time: time.Now(), Benchmarks: results}
+
+	benchMu.Lock()
+	benchLast[project] = run
+	benchMu.Unlock()
+
+	if err := c.renderBench(project); err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, err)
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoBench", fmt.Sprintf("%d benchmarks", len(results)))
+}
+
+// renderBench renders the last benchmark run of the project to the benchmark buffer.
+func (c *Commands) renderBench(project string) error {
+	benchMu.Lock()
+	defer benchMu.Unlock()
+
+	run := benchLast[project]
+	if run == nil {
+		return nil
+	}
+
+	var baseline *benchRun
+	name := benchBaseline[project]
+	if name != "" {
+		var err error
+		baseline, err = loadBenchBaseline(project, name)
+		if err != nil {
+			return err
+		}
+	}
+	// the baseline is selected per project, but it can be compared only with
+	// the same package
+	var mismatch error
+	if baseline != nil && baseline.Package != run.Package {
+		mismatch = errors.Errorf("%s baseline is the benchmarks of %s, not %s", name, baseline.Package, run.Package)
+		baseline, name = nil, ""
+	}
+
+	buf, err := c.openScratch(benchBuf, benchBufName, nvimutil.FiletypeGoTest, fmt.Sprintf("silent %s %s", config.TerminalPosition, config.TerminalMode), scratchOption(nvimutil.FiletypeGoTest))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	benchBuf = buf
+
+	if err := replaceLines(c.Nvim, benchBuf.Buffer(), benchTable(run, baseline, name)); err != nil {
+		return err
+	}
+
+	return mismatch
+}
+
+// ----------------------------------------------------------------------------
+// GoBenchSave
+
+func (c *Commands) cmdBenchSave(args []string, dir string) {
+	go c.BenchSave(args, dir)
+}
+
+// BenchSave saves the last benchmark run of the current buffer's project as
+// the args[0] named baseline, and selects that baseline.
+func (c *Commands) BenchSave(args []string, dir string) error {
+	name := args[0]
+	if !validBaselineName(name) {
+		return nvimutil.ErrorWrap(c.Nvim, errors.Errorf("invalid baseline name: %s", name))
+	}
+	project := storage.ProjectRoot(context.NewBuildContext(dir), dir)
+
+	benchMu.Lock()
+	run := benchLast[project]
+	benchMu.Unlock()
+	if run == nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.New("not found the benchmark result, run GoBench first"))
+	}
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+	fname := benchBaselineFile(project, name)
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+	if err := ioutil.WriteFile(fname, data, 0644); err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+
+	benchMu.Lock()
+	benchBaseline[project] = name
+	benchMu.Unlock()
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoBenchSave", fmt.Sprintf("saved %s baseline", name))
+}
+
+// ----------------------------------------------------------------------------
+// GoBenchBaseline
+
+func (c *Commands) cmdBenchBaseline(args []string, dir string) {
+	go c.BenchBaseline(args, dir)
+}
+
+// BenchBaseline selects the args[0] named baseline for the comparison of the
+// current buffer's project, or clears the selection if args is empty.
+func (c *Commands) BenchBaseline(args []string, dir string) error {
+	project := storage.ProjectRoot(context.NewBuildContext(dir), dir)
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+		if !validBaselineName(name) {
+			return nvimutil.ErrorWrap(c.Nvim, errors.Errorf("invalid baseline name: %s", name))
+		}
+		if _, err := loadBenchBaseline(project, name); err != nil {
+			return nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}
+
+	benchMu.Lock()
+	benchBaseline[project] = name
+	benchMu.Unlock()
+
+	if err := c.renderBench(project); err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, err)
+	}
+
+	if name == "" {
+		return nvimutil.EchoSuccess(c.Nvim, "GoBenchBaseline", "cleared the baseline")
+	}
+	return nvimutil.EchoSuccess(c.Nvim, "GoBenchBaseline", fmt.Sprintf("selected %s baseline", name))
+}
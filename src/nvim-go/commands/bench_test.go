@@ -0,0 +1,105 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBench(t *testing.T) {
+	out := `goos: linux
+goarch: amd64
+pkg: foo.org/bar
+BenchmarkFoo-8   	 2000000	       612 ns/op	     128 B/op	       2 allocs/op
+BenchmarkBar/sub-8         	     100	  10234567 ns/op
+BenchmarkFoo-8   	 2000000	       620 ns/op	     128 B/op	       2 allocs/op
+PASS
+ok  	foo.org/bar	4.123s
+`
+	want := []*benchResult{
+		{Name: "BenchmarkFoo-8", NsPerOp: []float64{612, 620}, BytesPerOp: []float64{128, 128}, AllocsPerOp: []float64{2, 2}},
+		{Name: "BenchmarkBar/sub-8", NsPerOp: []float64{10234567}},
+	}
+
+	got, err := parseBench(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBench() = %v, want %v", got, want)
+	}
+}
+
+func TestWelchSignificant(t *testing.T) {
+	type args struct {
+		a []float64
+		b []float64
+	}
+	tests := []struct {
+		name            string
+		args            args
+		wantSignificant bool
+		wantOk          bool
+	}{
+		{
+			name:            "significant",
+			args:            args{a: []float64{100, 101, 99, 100, 100}, b: []float64{120, 121, 119, 120, 120}},
+			wantSignificant: true,
+			wantOk:          true,
+		},
+		{
+			name:            "not significant",
+			args:            args{a: []float64{100, 130, 80, 110, 90}, b: []float64{105, 125, 85, 115, 95}},
+			wantSignificant: false,
+			wantOk:          true,
+		},
+		{
+			name:            "no variance",
+			args:            args{a: []float64{2, 2}, b: []float64{3, 3}},
+			wantSignificant: true,
+			wantOk:          true,
+		},
+		{
+			name:            "not enough samples",
+			args:            args{a: []float64{100}, b: []float64{120, 121}},
+			wantSignificant: false,
+			wantOk:          false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			gotSignificant, gotOk := welchSignificant(newBenchStat(tt.args.a), newBenchStat(tt.args.b))
+			if gotSignificant != tt.wantSignificant || gotOk != tt.wantOk {
+				t.Errorf("welchSignificant() = %v, %v, want %v, %v", gotSignificant, gotOk, tt.wantSignificant, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestBenchDelta(t *testing.T) {
+	tests := []struct {
+		name string
+		old  []float64
+		new  []float64
+		want string
+	}{
+		{name: "faster", old: []float64{200}, new: []float64{150}, want: "-25.00%"},
+		{name: "slower", old: []float64{100}, new: []float64{110}, want: "+10.00%"},
+		{name: "zero", old: []float64{0}, new: []float64{0}, want: "+0.00%"},
+		{name: "from zero", old: []float64{0}, new: []float64{1}, want: "+Inf%"},
+		{name: "no samples", old: nil, new: []float64{1}, want: "-"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := benchDelta(newBenchStat(tt.old), newBenchStat(tt.new)); got != tt.want {
+				t.Errorf("benchDelta(%v, %v) = %v, want %v", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// scratchOption returns the buffer and window options of the nvim-go
+// scratch buffer which is not related to a file.
+func scratchOption(filetype string) map[nvimutil.NvimOption]map[string]interface{} {
+	option := make(map[nvimutil.NvimOption]map[string]interface{})
+	bufoption := make(map[string]interface{})
+	bufvar := make(map[string]interface{})
+	windowoption := make(map[string]interface{})
+
+	bufoption[nvimutil.BufOptionBufhidden] = nvimutil.BufhiddenDelete
+	bufoption[nvimutil.BufOptionBuflisted] = false
+	bufoption[nvimutil.BufOptionBuftype] = nvimutil.BuftypeNofile
+	bufoption[nvimutil.BufOptionFiletype] = filetype
+	bufoption[nvimutil.BufOptionModifiable] = false
+	bufoption[nvimutil.BufOptionSwapfile] = false
+
+	bufvar[nvimutil.BufVarColorcolumn] = ""
+
+	windowoption[nvimutil.WinOptionList] = false
+	windowoption[nvimutil.WinOptionNumber] = false
+	windowoption[nvimutil.WinOptionRelativenumber] = false
+	windowoption[nvimutil.WinOptionWinfixheight] = true
+
+	option[nvimutil.BufferOption] = bufoption
+	option[nvimutil.BufferVar] = bufvar
+	option[nvimutil.WindowOption] = windowoption
+
+	return option
+}
+
+// openScratch opens the name scratch buffer with mode and option if buf is
+// nil or already wiped out, and keeps the focus to the current window.
+func (c *Commands) openScratch(buf *nvimutil.Buffer, name, filetype, mode string, option map[nvimutil.NvimOption]map[string]interface{}) (*nvimutil.Buffer, error) {
+	if buf != nil && nvimutil.IsBufferValid(c.Nvim, buf.Buffer()) {
+		return buf, nil
+	}
+
+	cw, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer c.Nvim.SetCurrentWindow(cw)
+
+	buf = nvimutil.NewBuffer(c.Nvim)
+	if err := buf.Create(name, filetype, mode, option); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return buf, nil
+}
+
+// appendLines appends the lines to the end of b buffer.
+// Replaces the first line instead of append if b is empty.
+func appendLines(v *nvim.Nvim, b nvim.Buffer, lines [][]byte) error {
+	defer nvimutil.Modifiable(v, b)()
+
+	lineCount, err := v.BufferLineCount(b)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if lineCount == 1 {
+		first, err := v.BufferLines(b, 0, 1, true)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(first) == 1 && len(first[0]) == 0 {
+			lineCount = 0
+		}
+	}
+
+	return v.SetBufferLines(b, lineCount, -1, true, lines)
+}
+
+// replaceLines replaces the all lines of b buffer to the lines.
+func replaceLines(v *nvim.Nvim, b nvim.Buffer, lines [][]byte) error {
+	defer nvimutil.Modifiable(v, b)()
+
+	return v.SetBufferLines(b, 0, -1, true, lines)
+}
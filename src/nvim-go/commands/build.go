@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
 	"nvim-go/nvimutil"
 
 	"github.com/neovim/go-client/nvim"
@@ -34,8 +35,8 @@ func (c *Commands) cmdBuild(bang bool, eval *CmdBuildEval) {
 		case error:
 			nvimutil.ErrorWrap(c.Nvim, e)
 		case []*nvim.QuickfixError:
-			c.ctx.Errlist["Build"] = e
-			nvimutil.ErrorList(c.Nvim, c.ctx.Errlist, true)
+			c.ctx.SetErrlist("Build", e)
+			nvimutil.ErrorList(c.Nvim, c.ctx.CopyErrlist(), true)
 		}
 	}()
 }
@@ -44,15 +45,29 @@ func (c *Commands) cmdBuild(bang bool, eval *CmdBuildEval) {
 // from the package directory structure.
 func (c *Commands) Build(bang bool, eval *CmdBuildEval) interface{} {
 	defer nvimutil.Profile(time.Now(), "GoBuild")
+	bctxt := context.NewBuildContext(filepath.Dir(eval.File))
+
+	if result := c.build(bctxt, bang, eval); result != nil {
+		return result
+	}
+
+	// Build succeeded, clean up the Errlist
+	c.ctx.DeleteErrlist("Build")
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoBuild", fmt.Sprintf("compiler: %s", bctxt.Build.Tool))
+}
+
+// build runs the compile tool for the eval.File package, and returns the error
+// or the []*nvim.QuickfixError if failed, or nil if succeeded.
+func (c *Commands) build(bctxt *context.BuildContext, bang bool, eval *CmdBuildEval) interface{} {
 	dir := filepath.Dir(eval.File)
-	defer c.ctx.SetContext(dir)()
 
 	if !bang {
 		bang = config.BuildForce
 	}
 
 	testFile := strings.HasSuffix(eval.File, "_test.go")
-	cmd, err := c.compileCmd(bang, dir, testFile)
+	cmd, err := c.compileCmd(bctxt, bang, dir, testFile)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -61,7 +76,7 @@ func (c *Commands) Build(bang bool, eval *CmdBuildEval) interface{} {
 
 	if buildErr := cmd.Run(); buildErr != nil {
 		if buildErr.(*exec.ExitError) != nil {
-			errlist, err := nvimutil.ParseError(stderr.Bytes(), eval.Cwd, &c.ctx.Build)
+			errlist, err := nvimutil.ParseError(stderr.Bytes(), eval.Cwd, bctxt)
 			if err != nil {
 				return errors.WithStack(err)
 			}
@@ -70,15 +85,12 @@ func (c *Commands) Build(bang bool, eval *CmdBuildEval) interface{} {
 		return buildErr
 	}
 
-	// Build succeeded, clean up the Errlist
-	delete(c.ctx.Errlist, "Build")
-
-	return nvimutil.EchoSuccess(c.Nvim, "GoBuild", fmt.Sprintf("compiler: %s", c.ctx.Build.Tool))
+	return nil
 }
 
 // compileCmd returns the *exec.Cmd corresponding to the compile tool.
-func (c *Commands) compileCmd(bang bool, dir string, testFile bool) (*exec.Cmd, error) {
-	bin, err := exec.LookPath(c.ctx.Build.Tool)
+func (c *Commands) compileCmd(bctxt *context.BuildContext, bang bool, dir string, testFile bool) (*exec.Cmd, error) {
+	bin, err := exec.LookPath(bctxt.Build.Command())
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -95,16 +107,17 @@ func (c *Commands) compileCmd(bang bool, dir string, testFile bool) (*exec.Cmd,
 
 	cmd := exec.Command(bin, mode)
 	cmd.Dir = dir
+	cmd.Env = bctxt.Env
 
-	switch c.ctx.Build.Tool {
-	case "go":
+	switch bctxt.Build.Tool {
+	case "go", "mod":
 		// Outputs the binary to DevNull if without bang
 		if !bang && !testFile {
 			args = append(args, "-o", os.DevNull)
 		}
 	case "gb":
 		if !testFile {
-			cmd.Dir = c.ctx.Build.ProjectRoot
+			cmd.Dir = bctxt.Build.ProjectRoot
 		}
 	}
 
@@ -205,15 +205,18 @@ func TestCommands_compileCmd(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
-		tt.fields.ctxt.Build.Tool = tt.want
-		tt.fields.ctxt.Build.ProjectRoot = tt.args.dir
-
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			c := NewCommands(tt.fields.Nvim, tt.fields.ctxt)
+			bctxt := &context.BuildContext{
+				Build: context.Build{
+					Tool:        filepath.Base(tt.want),
+					ProjectRoot: tt.args.dir,
+				},
+			}
 
-			got, err := c.compileCmd(tt.args.bang, tt.args.dir, tt.testfile)
+			got, err := c.compileCmd(bctxt, tt.args.bang, tt.args.dir, tt.testfile)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Commands.compileCmd(%v, %v) error = %v, wantErr %v", tt.args.bang, tt.args.dir, err, tt.wantErr)
 				return
@@ -32,18 +32,30 @@ func Register(p *plugin.Plugin, ctxt *context.Context) *Commands {
 	// Register command and function
 	// CommandOptions order: Name, NArgs, Range, Count, Addr, Bang, Register, Eval, Bar, Complete
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gobuild", Bang: true, Eval: "[getcwd(), expand('%:p')]"}, c.cmdBuild)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCoverage", Bang: true, Eval: "[getcwd(), expand('%:p')]"}, c.cmdCoverage)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCoverageClear"}, c.cmdCoverageClear)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCoverageToggle", Eval: "[getcwd(), expand('%:p')]"}, c.cmdCoverageToggle)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gofmt", Eval: "expand('%:p:h')"}, c.cmdFmt)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoGenerateTest", NArgs: "*", Range: "%", Addr: "line", Bang: true, Eval: "expand('%:p:h')", Complete: "file"}, c.cmdGenerateTest)
 	p.HandleFunction(&plugin.FunctionOptions{Name: "GoGuru", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2)]"}, c.funcGuru)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoIferr", Eval: "expand('%:p')"}, c.cmdIferr)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoInstall", Bang: true, Eval: "[getcwd(), expand('%:p')]"}, c.cmdInstall)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Golint", NArgs: "?", Eval: "expand('%:p')", Complete: "customlist,GoLintCompletion"}, c.cmdLint)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gometalinter", Eval: "getcwd()"}, c.cmdMetalinter)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gorename", NArgs: "?", Bang: true, Eval: "[getcwd(), expand('%:p'), expand('<cword>')]"}, c.cmdRename)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gorun", NArgs: "*", Eval: "expand('%:p')"}, c.cmdRun)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GorunLast", Eval: "expand('%:p')"}, c.cmdRunLast)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoBench", NArgs: "*", Eval: "expand('%:p:h')"}, c.cmdBench)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoBenchBaseline", NArgs: "?", Eval: "expand('%:p:h')"}, c.cmdBenchBaseline)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoBenchSave", NArgs: "1", Eval: "expand('%:p:h')"}, c.cmdBenchSave)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gotest", NArgs: "*", Eval: "expand('%:p:h')"}, c.cmdTest)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTestFunc", NArgs: "*", Eval: "[getcwd(), expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdTestFunc)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTestLast", Eval: "expand('%:p:h')"}, c.cmdTestLast)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTestHistory", NArgs: "?", Eval: "get(b:, 'go_test_history_dir', expand('%:p:h'))"}, c.cmdTestHistory)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoSwitchTest", Eval: "[getcwd(), expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdSwitchTest)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Govet", NArgs: "*", Eval: "[getcwd(), expand('%:p')]", Complete: "customlist,GoVetCompletion"}, c.cmdVet)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoWatch", Eval: "[getcwd(), expand('%:p')]"}, c.cmdWatch)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoWatchStop", Bang: true, Eval: "[getcwd(), expand('%:p')]"}, c.cmdWatchStop)
 
 	// Commnad completion
 	p.HandleFunction(&plugin.FunctionOptions{Name: "GoLintCompletion", Eval: "getcwd()"}, c.cmdLintComplete) // list the file, directory and go packages
@@ -52,6 +64,7 @@ func Register(p *plugin.Plugin, ctxt *context.Context) *Commands {
 	// for debug
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoByteOffset", Range: "%", Eval: "expand('%:p')"}, c.cmdByteOffset)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoBuffers"}, c.cmdBuffers)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoBuildCache"}, c.cmdBuildCache)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoWindows"}, c.cmdWindows)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoTabpages"}, c.cmdTabpagas)
 
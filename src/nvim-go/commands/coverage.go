@@ -0,0 +1,385 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/context"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const (
+	hlCoverageCovered   = "GoCoverageCovered"
+	hlCoverageUncovered = "GoCoverageUncovered"
+)
+
+// coverBlock represents a single block of the cover profile.
+type coverBlock struct {
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// coverage represents a state of the coverage highlighting.
+type coverage struct {
+	mu sync.Mutex
+	// srcID highlight source id of the coverage highlights.
+	srcID int
+	// buffers highlighted buffers.
+	buffers map[nvim.Buffer]bool
+}
+
+var coverageState = &coverage{buffers: make(map[nvim.Buffer]bool)}
+
+// ----------------------------------------------------------------------------
+// GoCoverage
+
+// cmdCoverageEval struct type for Eval of GoCoverage command.
+type cmdCoverageEval struct {
+	Cwd  string `msgpack:",array"`
+	File string
+}
+
+func (c *Commands) cmdCoverage(bang bool, eval *cmdCoverageEval) {
+	go c.Coverage(bang, eval)
+}
+
+// Coverage runs the go test command with -coverprofile flag for the current
+// buffer's package, and highlights the covered and uncovered blocks in every
+// open buffer of the package.
+// If bang is true, sets the per-function coverage to the locationlist.
+func (c *Commands) Coverage(bang bool, eval *cmdCoverageEval) error {
+	defer nvimutil.Profile(time.Now(), "GoCoverage")
+	dir := filepath.Dir(eval.File)
+	bctxt := context.NewBuildContext(dir)
+
+	pkgDir, err := pathutil.PackagePathContext(&bctxt.Context, dir)
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+	pkgID, err := pathutil.PackageIDContext(&bctxt.Context, dir)
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+
+	tmpfile, err := ioutil.TempFile("", "nvim-go-coverage")
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+	tmpfile.Close()
+	defer os.Remove(tmpfile.Name())
+
+	nvimutil.EchoProgress(c.Nvim, "GoCoverage", "testing %s", pkgID)
+
+	// gb test does not support the cover profile, but the gb project root and
+	// vendor are in the GOPATH of bctxt, so uses the go command same as the go
+	// project
+	cmd := exec.Command("go", "test", "-coverprofile="+tmpfile.Name())
+	cmd.Args = append(cmd.Args, config.TestFlags...)
+	cmd.Args = append(cmd.Args, pkgID)
+	cmd.Dir = pkgDir
+	cmd.Env = bctxt.Env
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if testErr := cmd.Run(); testErr != nil {
+		if _, ok := testErr.(*exec.ExitError); ok {
+			errlist, err := nvimutil.ParseError(out.Bytes(), eval.Cwd, bctxt)
+			if err == nil && len(errlist) > 0 {
+				c.ctx.SetErrlist("Coverage", errlist)
+				return nvimutil.ErrorList(c.Nvim, c.ctx.CopyErrlist(), true)
+			}
+			return nvimutil.ErrorWrap(c.Nvim, errors.New(strings.TrimSpace(out.String())))
+		}
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(testErr))
+	}
+	c.ctx.DeleteErrlist("Coverage")
+
+	f, err := os.Open(tmpfile.Name())
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+	defer f.Close()
+
+	profiles, err := parseCoverProfile(f)
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+	// The cover profile file name is the import path, all files of the package are in pkgDir.
+	blocks := make(map[string][]coverBlock)
+	for fname, b := range profiles {
+		blocks[filepath.Join(pkgDir, filepath.Base(fname))] = b
+	}
+
+	if err := c.highlightCoverage(blocks); err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+
+	if bang {
+		w, err := c.Nvim.CurrentWindow()
+		if err != nil {
+			return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+		}
+		loclist, err := funcCoverage(blocks, eval.Cwd)
+		if err != nil {
+			return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+		}
+		nvimutil.SetLoclist(c.Nvim, loclist)
+		nvimutil.OpenLoclist(c.Nvim, w, loclist, true)
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoCoverage", fmt.Sprintf("coverage: %.1f%% of statements", percentCovered(blocks)))
+}
+
+// highlightCoverage highlights the covered and uncovered blocks in every open buffers of the blocks.
+func (c *Commands) highlightCoverage(blocks map[string][]coverBlock) error {
+	coverageState.mu.Lock()
+	defer coverageState.mu.Unlock()
+
+	c.clearCoverage()
+
+	c.Nvim.Command("highlight default " + hlCoverageCovered + " ctermfg=green guifg=#A6E22E")
+	c.Nvim.Command("highlight default " + hlCoverageUncovered + " ctermfg=red guifg=#F92672")
+
+	bufs, err := c.Nvim.Buffers()
+	if err != nil {
+		return err
+	}
+	for _, b := range bufs {
+		name, err := c.Nvim.BufferName(b)
+		if err != nil {
+			continue
+		}
+		fileBlocks, ok := blocks[name]
+		if !ok {
+			continue
+		}
+		for _, block := range fileBlocks {
+			hlGroup := hlCoverageUncovered
+			if block.Count > 0 {
+				hlGroup = hlCoverageCovered
+			}
+			// The cover profile is 1-based and the end column is exclusive,
+			// nvim_buf_add_highlight is 0-based and -1 means the end of line.
+			for line := block.StartLine; line <= block.EndLine; line++ {
+				startCol, endCol := 0, -1
+				if line == block.StartLine {
+					startCol = block.StartCol - 1
+				}
+				if line == block.EndLine {
+					endCol = block.EndCol - 1
+				}
+				srcID, err := c.Nvim.AddBufferHighlight(b, coverageState.srcID, hlGroup, line-1, startCol, endCol)
+				if err != nil {
+					return err
+				}
+				coverageState.srcID = srcID
+			}
+		}
+		coverageState.buffers[b] = true
+	}
+
+	return nil
+}
+
+// clearCoverage clears the coverage highlights. Must be called with coverageState.mu held.
+func (c *Commands) clearCoverage() {
+	for b := range coverageState.buffers {
+		if nvimutil.IsBufferValid(c.Nvim, b) {
+			c.Nvim.ClearBufferHighlight(b, coverageState.srcID, 0, -1)
+		}
+		delete(coverageState.buffers, b)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// GoCoverageClear
+
+func (c *Commands) cmdCoverageClear() {
+	go c.CoverageClear()
+}
+
+// CoverageClear clears the coverage highlights of all buffers.
+func (c *Commands) CoverageClear() {
+	coverageState.mu.Lock()
+	defer coverageState.mu.Unlock()
+
+	c.clearCoverage()
+}
+
+// ----------------------------------------------------------------------------
+// GoCoverageToggle
+
+func (c *Commands) cmdCoverageToggle(eval *cmdCoverageEval) {
+	go c.CoverageToggle(eval)
+}
+
+// CoverageToggle clears the coverage highlights if highlighted, otherwise runs the Coverage.
+func (c *Commands) CoverageToggle(eval *cmdCoverageEval) error {
+	coverageState.mu.Lock()
+	highlighted := len(coverageState.buffers) > 0
+	coverageState.mu.Unlock()
+
+	if highlighted {
+		c.CoverageClear()
+		return nil
+	}
+	return c.Coverage(false, eval)
+}
+
+// ----------------------------------------------------------------------------
+// cover profile
+
+// parseCoverProfile parses the cover profile generated by the go test -coverprofile flag.
+// The format of the profile is:
+//  mode: set
+//  name.go:line.column,line.column numberOfStatements count
+func parseCoverProfile(r io.Reader) (map[string][]coverBlock, error) {
+	profiles := make(map[string][]coverBlock)
+
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		line := scan.Text()
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		i := strings.LastIndex(line, ":")
+		if i < 0 {
+			return nil, errors.Errorf("invalid cover profile line: %s", line)
+		}
+		fname := line[:i]
+
+		var b coverBlock
+		if _, err := fmt.Sscanf(line[i+1:], "%d.%d,%d.%d %d %d", &b.StartLine, &b.StartCol, &b.EndLine, &b.EndCol, &b.NumStmt, &b.Count); err != nil {
+			return nil, errors.Wrapf(err, "invalid cover profile line: %s", line)
+		}
+
+		// Merge the duplicate blocks if the profile was generated with multiple packages
+		blocks := profiles[fname]
+		if n := len(blocks); n > 0 && blocks[n-1].StartLine == b.StartLine && blocks[n-1].StartCol == b.StartCol &&
+			blocks[n-1].EndLine == b.EndLine && blocks[n-1].EndCol == b.EndCol {
+			blocks[n-1].Count += b.Count
+			continue
+		}
+		profiles[fname] = append(blocks, b)
+	}
+	if err := scan.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return profiles, nil
+}
+
+// percentCovered returns the percentage of the covered statements.
+func percentCovered(blocks map[string][]coverBlock) float64 {
+	var total, covered int
+	for _, fileBlocks := range blocks {
+		for _, b := range fileBlocks {
+			total += b.NumStmt
+			if b.Count > 0 {
+				covered += b.NumStmt
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100
+}
+
+// funcCoverage returns the per-function coverage as the locationlist.
+func funcCoverage(blocks map[string][]coverBlock, cwd string) ([]*nvim.QuickfixError, error) {
+	fnames := make([]string, 0, len(blocks))
+	for fname := range blocks {
+		fnames = append(fnames, fname)
+	}
+	sort.Strings(fnames)
+
+	var loclist []*nvim.QuickfixError
+	for _, fname := range fnames {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, fname, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+
+			var total, covered int
+			for _, b := range blocks[fname] {
+				if b.StartLine < start.Line || (b.StartLine == start.Line && b.StartCol < start.Column) {
+					continue
+				}
+				if b.EndLine > end.Line || (b.EndLine == end.Line && b.EndCol > end.Column) {
+					continue
+				}
+				total += b.NumStmt
+				if b.Count > 0 {
+					covered += b.NumStmt
+				}
+			}
+			percent := 0.0
+			if total > 0 {
+				percent = float64(covered) / float64(total) * 100
+			}
+
+			loclist = append(loclist, &nvim.QuickfixError{
+				FileName: pathutil.Rel(cwd, fname),
+				LNum:     start.Line,
+				Col:      start.Column,
+				Text:     funcDeclName(fn) + ": " + strconv.FormatFloat(percent, 'f', 1, 64) + "%",
+			})
+		}
+	}
+
+	return loclist, nil
+}
+
+// funcDeclName returns the function name with the receiver type name if method.
+func funcDeclName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
@@ -0,0 +1,115 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+var (
+	cover     = filepath.Join(testGoPath, "src", "cover")
+	coverMain = filepath.Join(cover, "cover.go")
+)
+
+const coverProfile = `mode: set
+cover/cover.go:4.21,5.11 1 1
+cover/cover.go:5.11,7.3 1 0
+cover/cover.go:8.2,8.10 1 1
+cover/cover.go:14.27,16.2 1 0
+`
+
+func TestParseCoverProfile(t *testing.T) {
+	type args struct {
+		profile string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string][]coverBlock
+		wantErr bool
+	}{
+		{
+			name: "set mode",
+			args: args{profile: coverProfile},
+			want: map[string][]coverBlock{
+				"cover/cover.go": {
+					{StartLine: 4, StartCol: 21, EndLine: 5, EndCol: 11, NumStmt: 1, Count: 1},
+					{StartLine: 5, StartCol: 11, EndLine: 7, EndCol: 3, NumStmt: 1, Count: 0},
+					{StartLine: 8, StartCol: 2, EndLine: 8, EndCol: 10, NumStmt: 1, Count: 1},
+					{StartLine: 14, StartCol: 27, EndLine: 16, EndCol: 2, NumStmt: 1, Count: 0},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "invalid line",
+			args:    args{profile: "mode: set\ncover/cover.go:4.21 1\n"},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCoverProfile(strings.NewReader(tt.args.profile))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCoverProfile(%v) error = %v, wantErr %v", tt.args.profile, err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCoverProfile(%v) = %v, want %v", tt.args.profile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuncCoverage(t *testing.T) {
+	profiles, err := parseCoverProfile(strings.NewReader(coverProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		blocks map[string][]coverBlock
+		cwd    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []*nvim.QuickfixError
+		wantErr bool
+	}{
+		{
+			name: "cover",
+			args: args{
+				blocks: map[string][]coverBlock{coverMain: profiles["cover/cover.go"]},
+				cwd:    cover,
+			},
+			want: []*nvim.QuickfixError{
+				{FileName: "cover.go", LNum: 4, Col: 1, Text: "Abs: 66.7%"},
+				{FileName: "cover.go", LNum: 14, Col: 1, Text: "T.Name: 0.0%"},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := funcCoverage(tt.args.blocks, tt.args.cwd)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("funcCoverage(%v, %v) error = %v, wantErr %v", tt.args.blocks, tt.args.cwd, err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("funcCoverage(%v, %v) = %v, want %v", tt.args.blocks, tt.args.cwd, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,712 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"nvim-go/context"
+	"nvim-go/internal/storage"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	delveterm "github.com/derekparker/delve/pkg/terminal"
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Breakpoints define breakpoints list buffer name.
+	Breakpoints nvimutil.BufferName = "breakpoints"
+
+	// breakpointSignBase base of the breakpoint sign ID, which avoids the
+	// conflict with the pc sign that uses the thread ID.
+	breakpointSignBase = 1000
+	// breakpointsDirVar buffer variable name of the breakpoints list buffer
+	// which stores the directory of the listed project.
+	breakpointsDirVar = "dlv_breakpoints_dir"
+
+	signBreakpoint            = "delve_bp"
+	signBreakpointConditional = "delve_bp_cond"
+	signBreakpointDisabled    = "delve_bp_disabled"
+	signTracepoint            = "delve_tp"
+)
+
+// breakpoint represents a persistent breakpoint of the project.
+type breakpoint struct {
+	// ID unique ID of the breakpoint in the project, which is not same as the
+	// delve server breakpoint ID.
+	ID int `json:"id"`
+	// File and Line location of the breakpoint. Resolved location by the delve
+	// server if the breakpoint is set to the function.
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+	// FunctionName function name of the breakpoint, takes precedence over the
+	// File and Line location.
+	FunctionName string `json:"functionName,omitempty"`
+	// Cond breakpoint condition expression.
+	Cond string `json:"cond,omitempty"`
+	// HitCond hit count condition such as ">= 3".
+	HitCond string `json:"hitCond,omitempty"`
+	// Tracepoint whether the breakpoint only prints the hit and continues.
+	Tracepoint bool `json:"tracepoint,omitempty"`
+	// Variables expressions to evaluate when the breakpoint is hit.
+	Variables []string `json:"variables,omitempty"`
+	// Disabled whether the breakpoint is not set to the delve server.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// signName returns the sign name of bp.
+func (bp *breakpoint) signName() string {
+	switch {
+	case bp.Disabled:
+		return signBreakpointDisabled
+	case bp.Tracepoint:
+		return signTracepoint
+	case bp.Cond != "" || bp.HitCond != "":
+		return signBreakpointConditional
+	}
+	return signBreakpoint
+}
+
+// signID returns the sign ID of bp.
+func (bp *breakpoint) signID() int {
+	return breakpointSignBase + bp.ID
+}
+
+// location returns the location spec of bp for the display.
+func (bp *breakpoint) location(dir string) string {
+	var loc string
+	if bp.File != "" {
+		loc = fmt.Sprintf("%s:%d", pathutil.ShortFilePath(bp.File, dir), bp.Line)
+	}
+	if bp.FunctionName != "" {
+		loc = strings.TrimSpace(bp.FunctionName + "() " + loc)
+	}
+	return loc
+}
+
+// String returns the description of bp for the display.
+func (bp *breakpoint) String(dir string) string {
+	var buf bytes.Buffer
+	if bp.Tracepoint {
+		buf.WriteString("trace ")
+	}
+	buf.WriteString(bp.location(dir))
+	if bp.Cond != "" {
+		buf.WriteString(" if " + bp.Cond)
+	}
+	if bp.HitCond != "" {
+		buf.WriteString(" hits " + bp.HitCond)
+	}
+	if len(bp.Variables) > 0 {
+		buf.WriteString(" print " + strings.Join(bp.Variables, ", "))
+	}
+	return buf.String()
+}
+
+// hitCondRe matches the hit count condition such as ">= 3" or "% 2".
+var hitCondRe = regexp.MustCompile(`^\s*(==|!=|<=|>=|<|>|%)?\s*(\d+)\s*$`)
+
+// hitCondSatisfied reports whether the hits satisfies the hit count condition.
+// The cond without operator is same as "==".
+func hitCondSatisfied(cond string, hits uint64) (bool, error) {
+	m := hitCondRe.FindStringSubmatch(cond)
+	if m == nil {
+		return false, errors.Errorf("invalid hit count condition: %s", cond)
+	}
+	n, err := strconv.ParseUint(m[2], 10, 64)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	switch m[1] {
+	case "!=":
+		return hits != n, nil
+	case "<":
+		return hits < n, nil
+	case "<=":
+		return hits <= n, nil
+	case ">":
+		return hits > n, nil
+	case ">=":
+		return hits >= n, nil
+	case "%":
+		if n == 0 {
+			return false, errors.Errorf("invalid hit count condition: %s", cond)
+		}
+		return hits%n == 0, nil
+	}
+	return hits == n, nil
+}
+
+// breakpointsFile returns the breakpoints file path of the project.
+func breakpointsFile(project string) string {
+	return storage.DataPath("breakpoints", project, "breakpoints.json")
+}
+
+// breakpointsProject returns the project root of the dir.
+func breakpointsProject(dir string) string {
+	return storage.ProjectRoot(context.NewBuildContext(dir), dir)
+}
+
+// loadBreakpoints loads the breakpoints of fname.
+// Returns empty breakpoints if fname does not exist.
+func loadBreakpoints(fname string) ([]*breakpoint, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var bps []*breakpoint
+	if err := json.Unmarshal(data, &bps); err != nil {
+		return nil, errors.Wrapf(err, "invalid breakpoints file: %s", fname)
+	}
+
+	return bps, nil
+}
+
+// saveBreakpoints saves the bps to fname.
+func saveBreakpoints(fname string, bps []*breakpoint) error {
+	data, err := json.MarshalIndent(bps, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(ioutil.WriteFile(fname, data, 0644))
+}
+
+// nextBreakpointID returns the unused breakpoint ID of bps.
+func nextBreakpointID(bps []*breakpoint) int {
+	id := 0
+	for _, bp := range bps {
+		if bp.ID > id {
+			id = bp.ID
+		}
+	}
+	return id + 1
+}
+
+// signPlaceRe matches the sign line of the ":sign place" command output.
+var signPlaceRe = regexp.MustCompile(`line=(\d+)\s+id=(\d+)`)
+
+// syncBreakpointLines updates the line of bps to the line of the placed sign,
+// because the sign moves with the buffer edits.
+// Reports whether the any line was changed.
+func syncBreakpointLines(v *nvim.Nvim, bps []*breakpoint) bool {
+	files := make(map[string][]*breakpoint)
+	for _, bp := range bps {
+		if bp.File != "" {
+			files[bp.File] = append(files[bp.File], bp)
+		}
+	}
+
+	changed := false
+	for file, fbps := range files {
+		var loaded bool
+		if err := v.Call("bufloaded", &loaded, file); err != nil || !loaded {
+			continue
+		}
+		out, err := v.CommandOutput("sign place file=" + file)
+		if err != nil {
+			continue
+		}
+		if updateBreakpointLines(fbps, out) {
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// updateBreakpointLines updates the line of bps to the line of the sign in the
+// out of the ":sign place" command. Reports whether the any line was changed.
+func updateBreakpointLines(bps []*breakpoint, out string) bool {
+	lines := make(map[int]int) // map[sign ID]line
+	for _, m := range signPlaceRe.FindAllStringSubmatch(out, -1) {
+		line, _ := strconv.Atoi(m[1])
+		id, _ := strconv.Atoi(m[2])
+		lines[id] = line
+	}
+
+	changed := false
+	for _, bp := range bps {
+		if line, ok := lines[bp.signID()]; ok && line != bp.Line {
+			bp.Line = line
+			changed = true
+		}
+	}
+	return changed
+}
+
+// projectBreakpoints loads the breakpoints of the project, and saves it if the
+// lines were changed by the buffer edits.
+// The caller must be hold the d.bpMu lock.
+func (d *Delve) projectBreakpoints(v *nvim.Nvim, project string) ([]*breakpoint, error) {
+	fname := breakpointsFile(project)
+	bps, err := loadBreakpoints(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	if syncBreakpointLines(v, bps) {
+		if err := saveBreakpoints(fname, bps); err != nil {
+			return nil, err
+		}
+	}
+
+	return bps, nil
+}
+
+// breakpointSign returns the sign of the name, and defines the sign if not defined yet.
+func (d *Delve) breakpointSign(v *nvim.Nvim, name string) (*nvimutil.Sign, error) {
+	if d.bpSign == nil {
+		d.bpSign = make(map[string]*nvimutil.Sign)
+	}
+	if sign, ok := d.bpSign[name]; ok {
+		return sign, nil
+	}
+
+	var (
+		sign *nvimutil.Sign
+		err  error
+	)
+	switch name {
+	case signBreakpointConditional:
+		sign, err = nvimutil.NewSign(v, name, nvimutil.BreakpointCondSymbol, "delveBreakpointConditionalSign", "")
+	case signBreakpointDisabled:
+		sign, err = nvimutil.NewSign(v, name, nvimutil.BreakpointDisabledSymbol, "delveBreakpointDisabledSign", "")
+	case signTracepoint:
+		sign, err = nvimutil.NewSign(v, name, nvimutil.TracepointSymbol, "delveTracepointSign", "")
+	default:
+		sign, err = nvimutil.NewSign(v, name, nvimutil.BreakpointSymbol, "delveBreakpointSign", "")
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	d.bpSign[name] = sign
+
+	return sign, nil
+}
+
+// placeBreakpointSign places the sign of bp if the file of bp is loaded.
+func (d *Delve) placeBreakpointSign(v *nvim.Nvim, bp *breakpoint) error {
+	if bp.File == "" {
+		return nil
+	}
+	var loaded bool
+	if err := v.Call("bufloaded", &loaded, bp.File); err != nil || !loaded {
+		return nil
+	}
+
+	sign, err := d.breakpointSign(v, bp.signName())
+	if err != nil {
+		return err
+	}
+	// re-place the sign for change the sign name
+	sign.Unplace(v, bp.signID(), bp.File)
+
+	return sign.Place(v, bp.signID(), bp.Line, bp.File, false)
+}
+
+// unplaceBreakpointSign unplaces the sign of bp.
+func (d *Delve) unplaceBreakpointSign(v *nvim.Nvim, bp *breakpoint) {
+	if bp.File == "" {
+		return
+	}
+	if sign, err := d.breakpointSign(v, bp.signName()); err == nil {
+		sign.Unplace(v, bp.signID(), bp.File)
+	}
+}
+
+// running reports whether the debugging session is running.
+func (d *Delve) running() bool {
+	return d.client != nil && d.processPid != 0
+}
+
+// createBreakpoint sets bp to the delve server, and updates the location of
+// bp to the resolved location.
+// The caller must be hold the d.bpMu lock.
+func (d *Delve) createBreakpoint(bp *breakpoint) (*delveapi.Breakpoint, error) {
+	req := &delveapi.Breakpoint{
+		File:       bp.File,
+		Line:       bp.Line,
+		Cond:       bp.Cond,
+		Tracepoint: bp.Tracepoint,
+		Variables:  bp.Variables,
+	}
+	if bp.FunctionName != "" {
+		req.File = ""
+		req.FunctionName = bp.FunctionName
+		req.Line = -1
+	}
+	if bp.Tracepoint {
+		req.LoadArgs = &delveterm.ShortLoadConfig
+	}
+
+	created, err := d.client.CreateBreakpoint(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if d.bpIDs == nil {
+		d.bpIDs = make(map[int]int)
+	}
+	d.bpIDs[bp.ID] = created.ID
+	if bp.HitCond != "" {
+		if d.bpHitConds == nil {
+			d.bpHitConds = make(map[int]string)
+		}
+		d.bpHitConds[created.ID] = bp.HitCond
+	}
+	bp.File = created.File
+	bp.Line = created.Line
+
+	return created, nil
+}
+
+// clearBreakpoint clears bp from the delve server if set.
+// The caller must be hold the d.bpMu lock.
+func (d *Delve) clearBreakpoint(bp *breakpoint) error {
+	id, ok := d.bpIDs[bp.ID]
+	if !ok {
+		return nil
+	}
+	delete(d.bpIDs, bp.ID)
+	delete(d.bpHitConds, id)
+
+	_, err := d.client.ClearBreakpoint(id)
+	return errors.WithStack(err)
+}
+
+// applyBreakpoints sets the enabled breakpoints of the project which are not
+// set yet to the delve server, and places the signs.
+// The breakpoints which could not set are printed to the terminal buffer.
+func (d *Delve) applyBreakpoints(v *nvim.Nvim) error {
+	d.bpMu.Lock()
+	defer d.bpMu.Unlock()
+
+	bps, err := d.projectBreakpoints(v, d.project)
+	if err != nil {
+		return err
+	}
+
+	var msgs []string
+	for _, bp := range bps {
+		if _, ok := d.bpIDs[bp.ID]; ok || bp.Disabled {
+			d.placeBreakpointSign(v, bp)
+			continue
+		}
+		created, err := d.createBreakpoint(bp)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("Breakpoint %s could not set: %v", bp.String(d.project), errors.Cause(err)))
+			continue
+		}
+		d.placeBreakpointSign(v, bp)
+		msgs = append(msgs, fmt.Sprintf("Breakpoint %d set at %#v for %s", created.ID, created.Addr, bp.String(d.project)))
+	}
+	if err := saveBreakpoints(breakpointsFile(d.project), bps); err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	return d.printTerminal("", []byte(strings.Join(msgs, "\n")))
+}
+
+// ----------------------------------------------------------------------------
+// break(breakpoint)
+
+// breakpointEval represent a breakpoint commands Eval args.
+type breakpointEval struct {
+	File string `msgpack:",array"`
+	Line int
+}
+
+func (d *Delve) cmdBreakpoint(v *nvim.Nvim, args []string, eval *breakpointEval) {
+	go d.breakpoint(v, args, eval)
+}
+
+// parseArgs parses the "DlvBreakpoint" command args.
+// The args syntax is:
+//  [-trace] [-hits {cond}] [-print {expr}]... [{function}] [if {expr}]
+// The breakpoint is set to the cursor line if the function is not specified.
+func (d *Delve) parseArgs(v *nvim.Nvim, args []string, eval *breakpointEval) (*breakpoint, error) {
+	bp := &breakpoint{
+		File: eval.File,
+		Line: eval.Line,
+	}
+
+	// Ref: https://github.com/derekparker/delve/blob/master/Documentation/cli/locspec.md
+	var funcName string
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "-trace":
+			bp.Tracepoint = true
+		case "-hits", "-print":
+			if i+1 >= len(args) {
+				return nil, errors.Errorf("%s requires the argument", arg)
+			}
+			i++
+			if arg == "-print" {
+				bp.Variables = append(bp.Variables, args[i])
+				continue
+			}
+			bp.HitCond = args[i]
+			// allows the space between the operator and count such as "-hits >= 3"
+			if hitCondRe.FindStringSubmatch(bp.HitCond) == nil && i+1 < len(args) {
+				i++
+				bp.HitCond += " " + args[i]
+			}
+			if _, err := hitCondSatisfied(bp.HitCond, 0); err != nil {
+				return nil, err
+			}
+		case "if":
+			bp.Cond = strings.Join(args[i+1:], " ")
+			if bp.Cond == "" {
+				return nil, errors.New("if requires the condition expression")
+			}
+			i = len(args)
+		default:
+			if funcName != "" {
+				return nil, errors.New("Too many arguments")
+			}
+			funcName = arg
+		}
+	}
+
+	if funcName != "" {
+		bp.File = ""
+		bp.Line = 0
+		bp.FunctionName = funcName
+	}
+
+	return bp, nil
+}
+
+// breakpoint sets a breakpoint, and sets marker to Nvim sign area.
+// The breakpoint is saved to the project breakpoints, and set to the delve
+// server if the debugging session is running.
+// Note that 'break' name is reverved Go language spec.
+func (d *Delve) breakpoint(v *nvim.Nvim, args []string, eval *breakpointEval) error {
+	bp, err := d.parseArgs(v, args, eval)
+	if err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+
+	d.bpMu.Lock()
+	defer d.bpMu.Unlock()
+
+	project := breakpointsProject(filepath.Dir(eval.File))
+	if d.running() {
+		project = d.project
+	}
+	bps, err := d.projectBreakpoints(v, project)
+	if err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	for _, b := range bps {
+		if (bp.FunctionName == "" && b.FunctionName == "" && b.File == bp.File && b.Line == bp.Line) ||
+			(bp.FunctionName != "" && b.FunctionName == bp.FunctionName) {
+			return nvimutil.ErrorWrap(v, errors.Errorf("breakpoint already exists at %s", b.location(project)))
+		}
+	}
+	bp.ID = nextBreakpointID(bps)
+
+	var msg string
+	if d.running() && !d.readOnly() {
+		created, err := d.createBreakpoint(bp)
+		if err != nil {
+			return nvimutil.ErrorWrap(v, err)
+		}
+		filename := pathutil.ShortFilePath(created.File, filepath.Dir(eval.File))
+		msg = fmt.Sprintf("Breakpoint %d set at %#v for %s() %s:%d", created.ID, created.Addr, created.FunctionName, filename, created.Line)
+	}
+
+	if err := saveBreakpoints(breakpointsFile(project), append(bps, bp)); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	if err := d.placeBreakpointSign(v, bp); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	if msg == "" {
+		return nil
+	}
+	if err := d.printTerminal("break "+bp.FunctionName, nvimutil.StrToByteSlice(msg)); err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// breakpoints
+
+func (d *Delve) cmdBreakpoints(v *nvim.Nvim, args []string, dir string) {
+	go d.breakpoints(v, args, dir)
+}
+
+// breakpoints lists the project breakpoints to the breakpoints buffer.
+// If args is "toggle {index}" or "delete {index}", toggles or deletes the
+// index of breakpoint instead of list.
+func (d *Delve) breakpoints(v *nvim.Nvim, args []string, dir string) error {
+	d.bpMu.Lock()
+	defer d.bpMu.Unlock()
+
+	project := breakpointsProject(dir)
+	if d.running() {
+		project = d.project
+	}
+	bps, err := d.projectBreakpoints(v, project)
+	if err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	if len(args) > 0 {
+		if len(args) != 2 {
+			return nvimutil.ErrorWrap(v, errors.New("usage: DlvBreakpoints [toggle|delete {index}]"))
+		}
+		idx, err := strconv.Atoi(args[1])
+		if err != nil || idx < 1 || idx > len(bps) {
+			return nvimutil.ErrorWrap(v, errors.Errorf("invalid breakpoint index: %s", args[1]))
+		}
+
+		switch args[0] {
+		case "toggle":
+			err = d.toggleBreakpoint(v, bps[idx-1])
+		case "delete":
+			err = d.deleteBreakpoint(v, bps[idx-1])
+			bps = append(bps[:idx-1], bps[idx:]...)
+		default:
+			err = errors.Errorf("unknown DlvBreakpoints action: %s", args[0])
+		}
+		if err != nil {
+			return nvimutil.ErrorWrap(v, err)
+		}
+		if err := saveBreakpoints(breakpointsFile(project), bps); err != nil {
+			return nvimutil.ErrorWrap(v, err)
+		}
+	}
+
+	return nvimutil.ErrorWrap(v, d.renderBreakpoints(v, bps, project))
+}
+
+// toggleBreakpoint toggles the enabled state of bp.
+// The caller must be hold the d.bpMu lock.
+func (d *Delve) toggleBreakpoint(v *nvim.Nvim, bp *breakpoint) error {
+	d.unplaceBreakpointSign(v, bp)
+	bp.Disabled = !bp.Disabled
+
+	if d.running() && !d.readOnly() {
+		var err error
+		if bp.Disabled {
+			err = d.clearBreakpoint(bp)
+		} else {
+			_, err = d.createBreakpoint(bp)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.placeBreakpointSign(v, bp)
+}
+
+// deleteBreakpoint clears bp from the delve server and unplaces the sign.
+// The caller must be hold the d.bpMu lock.
+func (d *Delve) deleteBreakpoint(v *nvim.Nvim, bp *breakpoint) error {
+	d.unplaceBreakpointSign(v, bp)
+
+	if d.running() && !d.readOnly() {
+		return d.clearBreakpoint(bp)
+	}
+	return nil
+}
+
+// renderBreakpoints renders the bps to the breakpoints buffer.
+// The line number of the buffer is the index of the breakpoint.
+func (d *Delve) renderBreakpoints(v *nvim.Nvim, bps []*breakpoint, project string) error {
+	if d.bpList == nil || !nvimutil.IsBufferValid(v, d.bpList.Buffer()) {
+		cw, err := v.CurrentWindow()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer v.SetCurrentWindow(cw)
+
+		d.bpList = nvimutil.NewBuffer(v)
+		if err := d.bpList.Create(string(Breakpoints), nvimutil.FiletypeDelve, "silent belowright 10 split", d.setTerminalOption()); err != nil {
+			return errors.WithStack(err)
+		}
+		nnoremap := map[string]string{
+			"t":  ":<C-u>execute 'DlvBreakpoints toggle' line('.')<CR>",
+			"dd": ":<C-u>execute 'DlvBreakpoints delete' line('.')<CR>",
+		}
+		if err := d.bpList.SetLocalMapping(nvimutil.NoremapNormal, nnoremap); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	// DlvBreakpoints in the breakpoints buffer uses the project instead of buffer name
+	if err := v.SetBufferVar(d.bpList.Buffer(), breakpointsDirVar, project); err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines := make([][]byte, len(bps))
+	for i, bp := range bps {
+		enabled := "x"
+		if bp.Disabled {
+			enabled = " "
+		}
+		lines[i] = []byte(fmt.Sprintf("[%s] %s", enabled, bp.String(project)))
+	}
+
+	defer nvimutil.Modifiable(v, d.bpList.Buffer())()
+
+	return v.SetBufferLines(d.bpList.Buffer(), 0, -1, true, lines)
+}
+
+// ----------------------------------------------------------------------------
+// autocmd BufReadPost
+
+func (d *Delve) cmdBufReadPost(v *nvim.Nvim, file string) {
+	go d.bufReadPost(v, file)
+}
+
+// bufReadPost places the signs of the project breakpoints in the file.
+func (d *Delve) bufReadPost(v *nvim.Nvim, file string) error {
+	d.bpMu.Lock()
+	defer d.bpMu.Unlock()
+
+	bps, err := loadBreakpoints(breakpointsFile(breakpointsProject(filepath.Dir(file))))
+	if err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	for _, bp := range bps {
+		if bp.File != file {
+			continue
+		}
+		if err := d.placeBreakpointSign(v, bp); err != nil {
+			return nvimutil.ErrorWrap(v, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHitCondSatisfied(t *testing.T) {
+	type args struct {
+		cond string
+		hits uint64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{name: "== satisfied", args: args{cond: "== 3", hits: 3}, want: true},
+		{name: "== not satisfied", args: args{cond: "==3", hits: 2}, want: false},
+		{name: "!= satisfied", args: args{cond: "!= 3", hits: 2}, want: true},
+		{name: "!= not satisfied", args: args{cond: "!= 3", hits: 3}, want: false},
+		{name: "< satisfied", args: args{cond: "< 3", hits: 2}, want: true},
+		{name: "< not satisfied", args: args{cond: "< 3", hits: 3}, want: false},
+		{name: "<= satisfied", args: args{cond: "<= 3", hits: 3}, want: true},
+		{name: "<= not satisfied", args: args{cond: "<= 3", hits: 4}, want: false},
+		{name: "> satisfied", args: args{cond: "> 3", hits: 4}, want: true},
+		{name: "> not satisfied", args: args{cond: "> 3", hits: 3}, want: false},
+		{name: ">= satisfied", args: args{cond: ">= 3", hits: 3}, want: true},
+		{name: ">= not satisfied", args: args{cond: ">= 3", hits: 2}, want: false},
+		{name: "% satisfied", args: args{cond: "% 2", hits: 4}, want: true},
+		{name: "% not satisfied", args: args{cond: "%2", hits: 3}, want: false},
+		{name: "% 0", args: args{cond: "% 0", hits: 0}, wantErr: true},
+		{name: "no operator satisfied", args: args{cond: "3", hits: 3}, want: true},
+		{name: "no operator not satisfied", args: args{cond: " 3 ", hits: 2}, want: false},
+		{name: "empty", args: args{cond: "", hits: 0}, wantErr: true},
+		{name: "unknown operator", args: args{cond: "=> 3", hits: 3}, wantErr: true},
+		{name: "no count", args: args{cond: ">=", hits: 3}, wantErr: true},
+		{name: "negative count", args: args{cond: "> -1", hits: 3}, wantErr: true},
+		{name: "not number", args: args{cond: "== foo", hits: 3}, wantErr: true},
+		{name: "overflow", args: args{cond: "== 18446744073709551616", hits: 3}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hitCondSatisfied(tt.args.cond, tt.args.hits)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("hitCondSatisfied(%q, %v) error = %v, wantErr %v", tt.args.cond, tt.args.hits, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("hitCondSatisfied(%q, %v) = %v, want %v", tt.args.cond, tt.args.hits, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelve_parseArgs(t *testing.T) {
+	eval := &breakpointEval{File: "/go/src/foo.org/foo/foo.go", Line: 12}
+	tests := []struct {
+		name    string
+		args    []string
+		want    *breakpoint
+		wantErr bool
+	}{
+		{
+			name: "cursor line",
+			args: nil,
+			want: &breakpoint{File: eval.File, Line: eval.Line},
+		},
+		{
+			name: "function",
+			args: []string{"main.main"},
+			want: &breakpoint{FunctionName: "main.main"},
+		},
+		{
+			name: "tracepoint",
+			args: []string{"-trace"},
+			want: &breakpoint{File: eval.File, Line: eval.Line, Tracepoint: true},
+		},
+		{
+			name: "hits without space",
+			args: []string{"-hits", ">=3"},
+			want: &breakpoint{File: eval.File, Line: eval.Line, HitCond: ">=3"},
+		},
+		{
+			name: "hits with space",
+			args: []string{"-hits", ">=", "3", "main.main"},
+			want: &breakpoint{FunctionName: "main.main", HitCond: ">= 3"},
+		},
+		{
+			name: "multiple print",
+			args: []string{"-print", "i", "-print", "s.Name"},
+			want: &breakpoint{File: eval.File, Line: eval.Line, Variables: []string{"i", "s.Name"}},
+		},
+		{
+			name: "condition",
+			args: []string{"main.main", "if", "i", "==", "3"},
+			want: &breakpoint{FunctionName: "main.main", Cond: "i == 3"},
+		},
+		{
+			name: "all options",
+			args: []string{"-trace", "-hits", "% 2", "-print", "i", "main.loop", "if", "i > 0"},
+			want: &breakpoint{FunctionName: "main.loop", Tracepoint: true, HitCond: "% 2", Variables: []string{"i"}, Cond: "i > 0"},
+		},
+		{
+			name:    "hits without argument",
+			args:    []string{"-hits"},
+			wantErr: true,
+		},
+		{
+			name:    "print without argument",
+			args:    []string{"-print"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid hits",
+			args:    []string{"-hits", ">=", "foo"},
+			wantErr: true,
+		},
+		{
+			name:    "hits % 0",
+			args:    []string{"-hits", "% 0"},
+			wantErr: true,
+		},
+		{
+			name:    "if without condition",
+			args:    []string{"main.main", "if"},
+			wantErr: true,
+		},
+		{
+			name:    "too many functions",
+			args:    []string{"main.main", "main.loop"},
+			wantErr: true,
+		},
+	}
+	d := &Delve{}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.parseArgs(nil, tt.args, eval)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseArgs(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateBreakpointLines(t *testing.T) {
+	const out = `
+--- Signs ---
+Signs for /go/src/foo.org/foo/foo.go:
+    line=10  id=1001  name=delve_bp  priority=10
+    line=25  id=1003  name=delve_bp_cond  priority=10
+    line=30  id=2  name=delve_pc  priority=10
+`
+	tests := []struct {
+		name      string
+		bps       []*breakpoint
+		wantLines []int
+		want      bool
+	}{
+		{
+			name:      "moved",
+			bps:       []*breakpoint{{ID: 1, Line: 8}, {ID: 3, Line: 25}},
+			wantLines: []int{10, 25},
+			want:      true,
+		},
+		{
+			name:      "unchanged",
+			bps:       []*breakpoint{{ID: 1, Line: 10}, {ID: 3, Line: 25}},
+			wantLines: []int{10, 25},
+			want:      false,
+		},
+		{
+			name:      "sign not placed",
+			bps:       []*breakpoint{{ID: 2, Line: 5}},
+			wantLines: []int{5},
+			want:      false,
+		},
+		{
+			name:      "no breakpoints",
+			bps:       nil,
+			wantLines: []int{},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := updateBreakpointLines(tt.bps, out); got != tt.want {
+				t.Errorf("updateBreakpointLines() = %v, want %v", got, tt.want)
+			}
+			lines := []int{}
+			for _, bp := range tt.bps {
+				lines = append(lines, bp.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("updateBreakpointLines() lines = %v, want %v", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestNextBreakpointID(t *testing.T) {
+	tests := []struct {
+		name string
+		bps  []*breakpoint
+		want int
+	}{
+		{
+			name: "no breakpoints",
+			bps:  nil,
+			want: 1,
+		},
+		{
+			name: "sequential",
+			bps:  []*breakpoint{{ID: 1}, {ID: 2}},
+			want: 3,
+		},
+		{
+			name: "deleted breakpoint is not reused",
+			bps:  []*breakpoint{{ID: 4}, {ID: 1}},
+			want: 5,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextBreakpointID(tt.bps); got != tt.want {
+				t.Errorf("nextBreakpointID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
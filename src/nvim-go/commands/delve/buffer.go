@@ -20,6 +20,8 @@ const (
 	Context nvimutil.BufferName = "context"
 	// Threads define threads buffer name.
 	Threads nvimutil.BufferName = "thread"
+	// Watch define watch expressions buffer name.
+	Watch nvimutil.BufferName = "watch"
 )
 
 func (d *Delve) createDebugBuffer() error {
@@ -52,10 +54,38 @@ func (d *Delve) createDebugBuffer() error {
 
 		d.buffer[Context] = nvimutil.NewBuffer(d.Nvim)
 		d.buffer[Context].Create(string(Context), nvimutil.FiletypeDelve, fmt.Sprintf("silent belowright %d split", (height*2/3)), option)
+		// <CR> and o expands or collapses the variable of the cursor line
+		d.buffer[Context].SetLocalMapping(nvimutil.NoremapNormal, map[string]string{
+			"<CR>": fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvToggleVariable', line('.'))<CR>", config.ChannelID),
+			"o":    fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvToggleVariable', line('.'))<CR>", config.ChannelID),
+		})
 
 		d.buffer[Threads] = nvimutil.NewBuffer(d.Nvim)
 		d.buffer[Threads].Create(string(Threads), nvimutil.FiletypeDelve, fmt.Sprintf("silent belowright %d split", (height*1/5)), option)
 		d.Nvim.SetWindowOption(d.buffer[Threads].Window, "winfixheight", true)
+		// <CR> and o selects the goroutine or stack frame of the cursor line
+		d.buffer[Threads].SetLocalMapping(nvimutil.NoremapNormal, map[string]string{
+			"<CR>": fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvSelectFrame', line('.'))<CR>", config.ChannelID),
+			"o":    fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvSelectFrame', line('.'))<CR>", config.ChannelID),
+		})
+
+		d.buffer[Disassembly] = nvimutil.NewBuffer(d.Nvim)
+		d.buffer[Disassembly].Create(string(Disassembly), nvimutil.FiletypeDelve, fmt.Sprintf("silent belowright %d split", (height*1/5)), option)
+		d.Nvim.SetWindowOption(d.buffer[Disassembly].Window, "winfixheight", true)
+
+		d.buffer[Watch] = nvimutil.NewBuffer(d.Nvim)
+		d.buffer[Watch].Create(string(Watch), nvimutil.FiletypeDelve, fmt.Sprintf("silent belowright %d split", (height*1/5)), option)
+		d.Nvim.SetWindowOption(d.buffer[Watch].Window, "winfixheight", true)
+		// <CR> and o expands or collapses the watch expression of the cursor line
+		d.buffer[Watch].SetLocalMapping(nvimutil.NoremapNormal, map[string]string{
+			"<CR>": fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvToggleWatch', line('.'))<CR>", config.ChannelID),
+			"o":    fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvToggleWatch', line('.'))<CR>", config.ChannelID),
+		})
+
+		d.buffer[Output] = nvimutil.NewBuffer(d.Nvim)
+		d.buffer[Output].Create(string(Output), nvimutil.FiletypeDelve, fmt.Sprintf("silent belowright %d split", (height*1/5)), option)
+		d.Nvim.SetWindowOption(d.buffer[Output].Window, "winfixheight", true)
+		d.output.setBuffer(d.buffer[Output])
 
 	}()
 
@@ -67,6 +97,16 @@ func (d *Delve) createDebugBuffer() error {
 	return d.Pipeline.Wait()
 }
 
+// deleteDebugBuffer wipes out the debug buffers created by createDebugBuffer.
+func (d *Delve) deleteDebugBuffer() {
+	for name, buf := range d.buffer {
+		if buf.Bufnr != 0 {
+			d.Nvim.Command(fmt.Sprintf("silent! bwipeout %d", buf.Bufnr))
+		}
+		delete(d.buffer, name)
+	}
+}
+
 func (d *Delve) setTerminalOption() map[nvimutil.NvimOption]map[string]interface{} {
 	option := make(map[nvimutil.NvimOption]map[string]interface{})
 	bufoption := make(map[string]interface{})
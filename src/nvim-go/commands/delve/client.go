@@ -0,0 +1,257 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+
+	"github.com/derekparker/delve/service/api"
+	delverpc2 "github.com/derekparker/delve/service/rpc2"
+)
+
+// rpcClient represents a JSON-RPC client of the delve APIv2 server on the conn.
+// The rpc2.RPCClient dials by itself and does not have the API for close the
+// connection, so the plugin holds the conn and calls the RPCServer directly.
+type rpcClient struct {
+	conn   net.Conn
+	client *rpc.Client
+}
+
+// newRPCClient returns the new rpcClient on the conn.
+func newRPCClient(conn net.Conn) (*rpcClient, error) {
+	c := &rpcClient{conn: conn, client: jsonrpc.NewClient(conn)}
+	if err := c.call("SetApiVersion", api.SetAPIVersionIn{APIVersion: 2}, &api.SetAPIVersionOut{}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close closes the connection of the client.
+func (c *rpcClient) Close() error {
+	return c.client.Close()
+}
+
+func (c *rpcClient) ProcessPid() int {
+	out := new(delverpc2.ProcessPidOut)
+	c.call("ProcessPid", delverpc2.ProcessPidIn{}, out)
+	return out.Pid
+}
+
+func (c *rpcClient) Detach(kill bool) error {
+	out := new(delverpc2.DetachOut)
+	return c.call("Detach", delverpc2.DetachIn{Kill: kill}, out)
+}
+
+func (c *rpcClient) Restart() ([]api.DiscardedBreakpoint, error) {
+	out := new(delverpc2.RestartOut)
+	err := c.call("Restart", delverpc2.RestartIn{}, out)
+	return out.DiscardedBreakpoints, err
+}
+
+func (c *rpcClient) GetState() (*api.DebuggerState, error) {
+	var out delverpc2.StateOut
+	err := c.call("State", delverpc2.StateIn{}, &out)
+	return out.State, err
+}
+
+// Continue continues the debuggee until the breakpoint which is not the
+// tracepoint, same as the rpc2.RPCClient.
+func (c *rpcClient) Continue() <-chan *api.DebuggerState {
+	ch := make(chan *api.DebuggerState)
+	go func() {
+		for {
+			out := new(delverpc2.CommandOut)
+			err := c.call("Command", &api.DebuggerCommand{Name: api.Continue}, &out)
+			state := out.State
+			if err != nil {
+				state.Err = err
+			}
+			if state.Exited {
+				// error types cannot be marshalled, so resets the error here
+				state.Err = fmt.Errorf("Process %d has exited with status %d", c.ProcessPid(), state.ExitStatus)
+			}
+			ch <- &state
+			if err != nil || state.Exited {
+				close(ch)
+				return
+			}
+
+			isbreakpoint := false
+			istracepoint := true
+			for i := range state.Threads {
+				if state.Threads[i].Breakpoint != nil {
+					isbreakpoint = true
+					istracepoint = istracepoint && state.Threads[i].Breakpoint.Tracepoint
+				}
+			}
+			if !isbreakpoint || !istracepoint {
+				close(ch)
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func (c *rpcClient) command(cmd api.DebuggerCommand) (*api.DebuggerState, error) {
+	var out delverpc2.CommandOut
+	err := c.call("Command", cmd, &out)
+	return &out.State, err
+}
+
+func (c *rpcClient) Next() (*api.DebuggerState, error) {
+	return c.command(api.DebuggerCommand{Name: api.Next})
+}
+
+func (c *rpcClient) Step() (*api.DebuggerState, error) {
+	return c.command(api.DebuggerCommand{Name: api.Step})
+}
+
+func (c *rpcClient) StepOut() (*api.DebuggerState, error) {
+	return c.command(api.DebuggerCommand{Name: api.StepOut})
+}
+
+func (c *rpcClient) StepInstruction() (*api.DebuggerState, error) {
+	return c.command(api.DebuggerCommand{Name: api.StepInstruction})
+}
+
+func (c *rpcClient) SwitchThread(threadID int) (*api.DebuggerState, error) {
+	return c.command(api.DebuggerCommand{Name: api.SwitchThread, ThreadID: threadID})
+}
+
+func (c *rpcClient) Halt() (*api.DebuggerState, error) {
+	return c.command(api.DebuggerCommand{Name: api.Halt})
+}
+
+func (c *rpcClient) GetBreakpoint(id int) (*api.Breakpoint, error) {
+	var out delverpc2.GetBreakpointOut
+	err := c.call("GetBreakpoint", delverpc2.GetBreakpointIn{Id: id}, &out)
+	return &out.Breakpoint, err
+}
+
+func (c *rpcClient) GetBreakpointByName(name string) (*api.Breakpoint, error) {
+	var out delverpc2.GetBreakpointOut
+	err := c.call("GetBreakpoint", delverpc2.GetBreakpointIn{Name: name}, &out)
+	return &out.Breakpoint, err
+}
+
+func (c *rpcClient) CreateBreakpoint(bp *api.Breakpoint) (*api.Breakpoint, error) {
+	var out delverpc2.CreateBreakpointOut
+	err := c.call("CreateBreakpoint", delverpc2.CreateBreakpointIn{Breakpoint: *bp}, &out)
+	return &out.Breakpoint, err
+}
+
+func (c *rpcClient) ListBreakpoints() ([]*api.Breakpoint, error) {
+	var out delverpc2.ListBreakpointsOut
+	err := c.call("ListBreakpoints", delverpc2.ListBreakpointsIn{}, &out)
+	return out.Breakpoints, err
+}
+
+func (c *rpcClient) ClearBreakpoint(id int) (*api.Breakpoint, error) {
+	var out delverpc2.ClearBreakpointOut
+	err := c.call("ClearBreakpoint", delverpc2.ClearBreakpointIn{Id: id}, &out)
+	return out.Breakpoint, err
+}
+
+func (c *rpcClient) AmendBreakpoint(bp *api.Breakpoint) error {
+	out := new(delverpc2.AmendBreakpointOut)
+	return c.call("AmendBreakpoint", delverpc2.AmendBreakpointIn{Breakpoint: *bp}, out)
+}
+
+func (c *rpcClient) ListThreads() ([]*api.Thread, error) {
+	var out delverpc2.ListThreadsOut
+	err := c.call("ListThreads", delverpc2.ListThreadsIn{}, &out)
+	return out.Threads, err
+}
+
+func (c *rpcClient) EvalVariable(scope api.EvalScope, expr string, cfg api.LoadConfig) (*api.Variable, error) {
+	var out delverpc2.EvalOut
+	err := c.call("Eval", delverpc2.EvalIn{Scope: scope, Expr: expr, Cfg: &cfg}, &out)
+	return out.Variable, err
+}
+
+func (c *rpcClient) SetVariable(scope api.EvalScope, symbol, value string) error {
+	out := new(delverpc2.SetOut)
+	return c.call("Set", delverpc2.SetIn{Scope: scope, Symbol: symbol, Value: value}, out)
+}
+
+func (c *rpcClient) ListSources(filter string) ([]string, error) {
+	out := new(delverpc2.ListSourcesOut)
+	err := c.call("ListSources", delverpc2.ListSourcesIn{Filter: filter}, out)
+	return out.Sources, err
+}
+
+func (c *rpcClient) ListFunctions(filter string) ([]string, error) {
+	out := new(delverpc2.ListFunctionsOut)
+	err := c.call("ListFunctions", delverpc2.ListFunctionsIn{Filter: filter}, out)
+	return out.Funcs, err
+}
+
+func (c *rpcClient) ListTypes(filter string) ([]string, error) {
+	out := new(delverpc2.ListTypesOut)
+	err := c.call("ListTypes", delverpc2.ListTypesIn{Filter: filter}, out)
+	return out.Types, err
+}
+
+func (c *rpcClient) ListPackageVariables(filter string, cfg api.LoadConfig) ([]api.Variable, error) {
+	var out delverpc2.ListPackageVarsOut
+	err := c.call("ListPackageVars", delverpc2.ListPackageVarsIn{Filter: filter, Cfg: cfg}, &out)
+	return out.Variables, err
+}
+
+func (c *rpcClient) ListLocalVariables(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error) {
+	var out delverpc2.ListLocalVarsOut
+	err := c.call("ListLocalVars", delverpc2.ListLocalVarsIn{Scope: scope, Cfg: cfg}, &out)
+	return out.Variables, err
+}
+
+func (c *rpcClient) ListRegisters(threadID int, includeFp bool) (api.Registers, error) {
+	out := new(delverpc2.ListRegistersOut)
+	err := c.call("ListRegisters", delverpc2.ListRegistersIn{ThreadID: threadID, IncludeFp: includeFp}, out)
+	return out.Regs, err
+}
+
+func (c *rpcClient) ListFunctionArgs(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error) {
+	var out delverpc2.ListFunctionArgsOut
+	err := c.call("ListFunctionArgs", delverpc2.ListFunctionArgsIn{Scope: scope, Cfg: cfg}, &out)
+	return out.Args, err
+}
+
+func (c *rpcClient) ListGoroutines() ([]*api.Goroutine, error) {
+	var out delverpc2.ListGoroutinesOut
+	err := c.call("ListGoroutines", delverpc2.ListGoroutinesIn{}, &out)
+	return out.Goroutines, err
+}
+
+func (c *rpcClient) Stacktrace(goroutineID, depth int, cfg *api.LoadConfig) ([]api.Stackframe, error) {
+	var out delverpc2.StacktraceOut
+	err := c.call("Stacktrace", delverpc2.StacktraceIn{Id: goroutineID, Depth: depth, Cfg: cfg}, &out)
+	return out.Locations, err
+}
+
+func (c *rpcClient) FindLocation(scope api.EvalScope, loc string) ([]api.Location, error) {
+	var out delverpc2.FindLocationOut
+	err := c.call("FindLocation", delverpc2.FindLocationIn{Scope: scope, Loc: loc}, &out)
+	return out.Locations, err
+}
+
+func (c *rpcClient) DisassembleRange(scope api.EvalScope, startPC, endPC uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error) {
+	var out delverpc2.DisassembleOut
+	err := c.call("Disassemble", delverpc2.DisassembleIn{Scope: scope, StartPC: startPC, EndPC: endPC, Flavour: flavour}, &out)
+	return out.Disassemble, err
+}
+
+func (c *rpcClient) DisassemblePC(scope api.EvalScope, pc uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error) {
+	var out delverpc2.DisassembleOut
+	err := c.call("Disassemble", delverpc2.DisassembleIn{Scope: scope, StartPC: pc, Flavour: flavour}, &out)
+	return out.Disassemble, err
+}
+
+func (c *rpcClient) call(method string, args, reply interface{}) error {
+	return c.client.Call("RPCServer."+method, args, reply)
+}
@@ -7,47 +7,102 @@ package delve
 import (
 	"bytes"
 	"fmt"
-	"go/build"
-	"io/ioutil"
-	"os"
+	"go/parser"
+	"go/token"
+	"net"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"nvim-go/context"
+	"nvim-go/internal/gotest"
 	"nvim-go/nvimutil"
 	"nvim-go/pathutil"
 
-	delveapi "github.com/derekparker/delve/service/api"
-	delverpc2 "github.com/derekparker/delve/service/rpc2"
 	delveterm "github.com/derekparker/delve/pkg/terminal"
+	delveapi "github.com/derekparker/delve/service/api"
 	"github.com/neovim/go-client/nvim"
 	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
 )
 
-const defaultAddr = "localhost:41222" // d:4 l:12 v:22
-
 // Delve represents a delve client.
 type Delve struct {
 	Nvim     *nvim.Nvim
 	Pipeline *nvim.Pipeline
 	Batch    *nvim.Batch
 
-	ctxt *context.Context
-
-	server     *exec.Cmd
-	client     *delverpc2.RPCClient
-	term       *delveterm.Term
-	debugger   *delveterm.Commands
+	ctxt  *context.Context
+	bctxt *context.BuildContext
+
+	// mode setup command of the debugging session, such as "debug" or "attach".
+	mode string
+	// project project root of the debugging session, which used for the
+	// persistent breakpoints.
+	project string
+	server  *exec.Cmd
+	// launched whether the plugin instance launched the headless server,
+	// which is the only case to kill the debuggee by DlvDetach.
+	launched   bool
+	client     *rpcClient
 	processPid int
-	serverOut  bytes.Buffer
-	serverErr  bytes.Buffer
+	// addr address of the dlv server.
+	addr string
+	// output streams the server stdout and stderr to the output buffer.
+	output     *outputWriter
+	outputTail *outputTail
+	// serverExited receives the exit status of the server process.
+	serverExited <-chan error
 
 	channelID int
 
 	Locals []delveapi.Variable
 
+	// execMu Mutex lock for the in progress execution control command.
+	execMu    sync.Mutex
+	executing string
+
+	// scopeMu Mutex lock for the selected goroutine and frame scope.
+	scopeMu sync.Mutex
+	scope   delveapi.EvalScope
+	// goroutineID goroutine ID of the stopped thread.
+	goroutineID int
+
+	// threadMu Mutex lock for the threads buffer lines.
+	threadMu    sync.Mutex
+	threadLines []threadLine
+	threadDir   string
+
+	// varMu Mutex lock for the variable inspector tree.
+	varMu sync.Mutex
+	vars  *varTree
+	// varStart line offset of the variable inspector tree in the context buffer.
+	varStart int
+
+	// watchMu Mutex lock for the watch expressions.
+	watchMu   sync.Mutex
+	watches   []string
+	watchTree *varTree
+	// watchValues last values of the watch expressions for highlight the changes.
+	watchValues map[string]string
+
+	// evalMu Mutex lock for the eval preview tree.
+	evalMu   sync.Mutex
+	evalTree *varTree
+
+	// bpMu Mutex lock for the persistent breakpoints.
+	bpMu  sync.Mutex
+	bpIDs map[int]int // map[breakpoint.ID]delveapi.Breakpoint.ID
+	// bpHitConds hit count conditions of the breakpoints, which are checked
+	// by the client because the delve server does not support it.
+	bpHitConds map[int]string // map[delveapi.Breakpoint.ID]condition
+
+	// stdinHistoryProject project of the DlvStdin command history which was
+	// added to the input history of the Neovim instance.
+	stdinHistoryProject string
+
 	BufferContext
 	SignContext
 }
@@ -57,19 +112,23 @@ type BufferContext struct {
 	cb     nvim.Buffer
 	cw     nvim.Window
 	buffer map[nvimutil.BufferName]*nvimutil.Buffer
+	bpList *nvimutil.Buffer
+	// evalPreview preview buffer of the DlvEval result.
+	evalPreview *nvimutil.Buffer
 }
 
 // SignContext represents a breakpoint and program counter sign.
 type SignContext struct {
-	bpSign map[int]*nvimutil.Sign // map[breakPoint.id]*nvim.Sign
+	bpSign map[string]*nvimutil.Sign // map[sign name]*nvim.Sign
 	pcSign *nvimutil.Sign
 }
 
 // NewDelve represents a delve client interface.
 func NewDelve(v *nvim.Nvim, ctxt *context.Context) *Delve {
 	return &Delve{
-		Nvim: v,
-		ctxt: ctxt,
+		Nvim:   v,
+		ctxt:   ctxt,
+		output: &outputWriter{v: v},
 	}
 }
 
@@ -79,16 +138,21 @@ func (d *Delve) init(v *nvim.Nvim, addr string) error {
 	if !strings.Contains(addr, ":") {
 		addr = "localhost:" + addr
 	}
-	d.client = delverpc2.NewClient(addr)           // *rpc2.RPCClient
-	d.term = delveterm.New(d.client, nil)          // *terminal.Term
-	d.debugger = delveterm.DebugCommands(d.client) // *terminal.Commands
-	d.processPid = d.client.ProcessPid()           // int
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	d.client, err = newRPCClient(conn)
+	if err != nil {
+		conn.Close()
+		return errors.WithStack(err)
+	}
+	d.processPid = d.client.ProcessPid() // int
 	if d.processPid == 0 {
 		return errors.New("Cannot setup delve server")
 	}
-	// avoid setup logs by assigning after server starts up
-	d.server.Stdout = &d.serverOut
-	d.server.Stderr = &d.serverErr
+	// avoid setup logs by starting the output streaming after server starts up
+	d.output.start()
 
 	return nil
 }
@@ -102,27 +166,72 @@ type delveEval struct {
 	Dir string
 }
 
-func (d *Delve) waitServer(addr string) error {
-	d.dialServer(d.Nvim, defaultAddr)
+// start starts the dlv debugging.
+// The server listens on the free port if the cfg.addr is empty.
+func (d *Delve) start(cmd string, cfg Config, eval *delveEval) (err error) {
+	d.bctxt = context.NewBuildContext(eval.Cwd)
+	d.mode = cmd
+	d.project = breakpointsProject(eval.Dir)
+
+	if cfg.addr == "" {
+		addr, err := freeAddr()
+		if err != nil {
+			return nvimutil.ErrorWrap(d.Nvim, err)
+		}
+		cfg.addr = addr
+	}
 
-	if err := d.init(d.Nvim, addr); err != nil {
-		return errors.WithStack(err)
+	if cmd == "connect" {
+		// dials the running server directly, there is no server to launch
+		d.server = nil
+		d.serverExited = nil
+	} else if err := d.startServer(cmd, cfg); err != nil {
+		return nvimutil.ErrorWrap(d.Nvim, errors.WithStack(err))
+	}
+	// tears down the server, buffers and client if the setup failed halfway
+	started := false
+	defer func() {
+		if err != nil && !started {
+			d.abort()
+		}
+	}()
+	// creates the debug buffers after the server is ready, to avoid the
+	// useless buffers if the build failed
+	if err := d.dialServer(d.Nvim, cfg.addr); err != nil {
+		// the build errors are reported to the location list without err
+		d.kill()
+		return d.reportServerError(d.Nvim, eval.Cwd, err)
+	}
+	d.ctxt.DeleteErrlist("Delve")
+	d.addr = cfg.addr
+	// the connect command does not launch the headless server
+	d.launched = cmd != "connect"
+	// remembers the server for the DlvReconnect after DlvDetach!
+	if err := saveSession(sessionFile(d.project), &session{Addr: cfg.addr, Mode: cmd}); err != nil {
+		return nvimutil.ErrorWrap(d.Nvim, err)
 	}
 
+	if err := d.createDebugBuffer(); err != nil {
+		return nvimutil.ErrorWrap(d.Nvim, errors.WithStack(err))
+	}
+	if err := d.init(d.Nvim, cfg.addr); err != nil {
+		return nvimutil.ErrorWrap(d.Nvim, errors.WithStack(err))
+	}
 	// TODO(zchee): check whether the exists terminal buffer created by d.createDebugBuffer()
-	return d.printTerminal("", []byte("Type 'help' for list of commands."))
-}
-
-// start starts the dlv debugging.
-func (d *Delve) start(cmd string, cfg Config, eval *delveEval) error {
-	defer d.ctxt.SetContext(eval.Cwd)()
-
-	if err := d.startServer(cmd, cfg); err != nil {
-		return errors.WithStack(err)
+	if err := d.printTerminal("", []byte("Type 'help' for list of commands.")); err != nil {
+		return nvimutil.ErrorWrap(d.Nvim, errors.WithStack(err))
+	}
+	started = true
+
+	switch cmd {
+	case "trace":
+		return d.trace(d.Nvim, cfg.pattern, eval.Dir)
+	case "core":
+		// the core dump can not set the breakpoints
+		return d.openCore(d.Nvim, eval.Dir)
 	}
-	defer d.waitServer(cfg.addr)
 
-	return d.createDebugBuffer()
+	return nvimutil.ErrorWrap(d.Nvim, d.applyBreakpoints(d.Nvim))
 }
 
 // ----------------------------------------------------------------------------
@@ -133,12 +242,17 @@ func (d *Delve) cmdAttach(v *nvim.Nvim, args []string, eval *delveEval) {
 	d.Pipeline = v.NewPipeline()
 	d.Batch = v.NewBatch()
 
-	pid, err := strconv.ParseInt(args[0], 10, 64)
+	if len(args) == 0 {
+		nvimutil.ErrorWrap(v, errors.New("DlvAttach requires the pid"))
+		return
+	}
+	pid, err := strconv.Atoi(args[0])
 	if err != nil {
-		nvimutil.ErrorWrap(v, err)
+		nvimutil.ErrorWrap(v, errors.WithStack(err))
+		return
 	}
 	cfg := Config{
-		pid:   int(pid),
+		pid:   pid,
 		flags: args[1:],
 	}
 	go d.start("attach", cfg, eval)
@@ -153,13 +267,16 @@ func (d *Delve) cmdConnect(v *nvim.Nvim, args []string, eval *delveEval) {
 	d.Pipeline = v.NewPipeline()
 	d.Batch = v.NewBatch()
 
+	if len(args) != 1 {
+		nvimutil.ErrorWrap(v, errors.New("DlvConnect requires the server address"))
+		return
+	}
 	addr := args[0]
 	if !strings.Contains(addr, ":") {
 		addr = "localhost:" + addr
 	}
 	cfg := Config{
-		addr:  addr,
-		flags: args[1:],
+		addr: addr,
 	}
 	go d.start("connect", cfg, eval)
 }
@@ -167,101 +284,226 @@ func (d *Delve) cmdConnect(v *nvim.Nvim, args []string, eval *delveEval) {
 // ----------------------------------------------------------------------------
 // debug
 
-func (d *Delve) findRootDir(dir string) string {
-	rootDir := pathutil.FindVCSRoot(dir)
-	srcPath := filepath.Join(build.Default.GOPATH, "src") + string(filepath.Separator)
-	return filepath.Clean(strings.TrimPrefix(rootDir, srcPath))
+// findRootDir returns the package path of the eval.Dir repository root, which
+// is relative from the GOPATH of the eval.Dir build context.
+func (d *Delve) findRootDir(eval *delveEval) string {
+	bctxt := context.NewBuildContext(eval.Dir)
+	rootDir := bctxt.Build.VCSRoot
+	if rootDir == "" {
+		rootDir = eval.Dir
+	}
+	for _, gopath := range filepath.SplitList(bctxt.Context.GOPATH) {
+		srcPath := filepath.Join(gopath, "src") + string(filepath.Separator)
+		if strings.HasPrefix(rootDir, srcPath) {
+			return filepath.Clean(strings.TrimPrefix(rootDir, srcPath))
+		}
+	}
+	return filepath.Clean(rootDir)
 }
 
 // cmdDebug setup the debugging.
-// TODO(zchee): If failed debug(build), even create each buffers.
 func (d *Delve) cmdDebug(v *nvim.Nvim, args []string, eval *delveEval) {
 	d.Pipeline = v.NewPipeline()
 	d.Batch = v.NewBatch()
 
 	cfg := Config{
-		path:  d.findRootDir(eval.Dir),
-		addr:  defaultAddr,
+		path:  d.findRootDir(eval),
 		flags: args,
 	}
 	go d.start("debug", cfg, eval)
 }
 
 // ----------------------------------------------------------------------------
-// break(breakpoint)
+// exec
 
-// breakpointEval represent a breakpoint commands Eval args.
-type breakpointEval struct {
-	File string `msgpack:",array"`
-}
+// cmdExec setup the debugging of the precompiled binary.
+// The first args is the binary path, and the rest of args are passed to the binary.
+func (d *Delve) cmdExec(v *nvim.Nvim, args []string, eval *delveEval) {
+	d.Pipeline = v.NewPipeline()
+	d.Batch = v.NewBatch()
 
-func (d *Delve) cmdBreakpoint(v *nvim.Nvim, args []string, eval *breakpointEval) {
-	go d.breakpoint(v, args, eval)
+	if len(args) == 0 {
+		nvimutil.ErrorWrap(v, errors.New("DlvExec requires the binary path"))
+		return
+	}
+	path := args[0]
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(eval.Cwd, path)
+	}
+	cfg := Config{
+		path: path,
+		args: args[1:],
+	}
+	go d.start("exec", cfg, eval)
 }
 
-// parseArgs parses the "DlvBreak" command args.
-func (d *Delve) parseArgs(v *nvim.Nvim, args []string, eval *breakpointEval) (*delveapi.Breakpoint, error) {
-	var bpInfo *delveapi.Breakpoint
+// ----------------------------------------------------------------------------
+// core
 
-	// Ref: https://github.com/derekparker/delve/blob/master/Documentation/cli/locspec.md
-	switch len(args) {
-	case 0:
-		cursor, err := v.WindowCursor(d.cw)
-		if err != nil {
-			return nil, errors.WithStack(err)
+// cmdCore setup the post-mortem debugging of the core dump.
+// The args are the executable path and the core file path.
+func (d *Delve) cmdCore(v *nvim.Nvim, args []string, eval *delveEval) {
+	d.Pipeline = v.NewPipeline()
+	d.Batch = v.NewBatch()
+
+	if len(args) != 2 {
+		nvimutil.ErrorWrap(v, errors.New("usage: DlvCore {executable} {corefile}"))
+		return
+	}
+	paths := make([]string, len(args))
+	for i, path := range args {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(eval.Cwd, path)
 		}
+		paths[i] = path
+	}
+	cfg := Config{
+		path: paths[0],
+		core: paths[1],
+	}
+	go d.start("core", cfg, eval)
+}
 
-		bpInfo = &delveapi.Breakpoint{
-			File: eval.File,
-			Line: cursor[0],
+// openCore shows the crashed location of the core dump, and populates the
+// goroutines, stack frames and variables of the debug buffers.
+func (d *Delve) openCore(v *nvim.Nvim, dir string) error {
+	state, err := d.client.GetState()
+	if err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+	// opens the crashed file on the source window, which may not be the current file
+	if cThread := state.CurrentThread; cThread != nil {
+		if err := d.showLocation(v, cThread.File, cThread.Line); err != nil {
+			return nvimutil.ErrorWrap(v, err)
 		}
-	case 1:
-		// FIXME(zchee): more elegant way
-		splitargs := strings.Split(args[0], ".")
-		splitargs[1] = fmt.Sprintf("%s%s", strings.ToUpper(splitargs[1][:1]), splitargs[1][1:])
-		name := strings.Join(splitargs, "")
-
-		bpInfo = &delveapi.Breakpoint{
-			Name:         name,
-			FunctionName: args[0],
+	}
+
+	return d.updateState(v, "core", dir, state, nil)
+}
+
+// ----------------------------------------------------------------------------
+// test
+
+// testEval represent a DlvTest command Eval args.
+type testEval struct {
+	Cwd    string `msgpack:",array"`
+	Dir    string
+	File   string
+	Offset int
+}
+
+// cmdTest setup the debugging of the current buffer's package test.
+// If args is empty, debugs only the test function of the current cursor, or
+// the all tests if the cursor is not in the test function.
+// The args such as "-run pattern" are passed to the test binary with the
+// "-test." prefix.
+func (d *Delve) cmdTest(v *nvim.Nvim, args []string, eval *testEval) {
+	d.Pipeline = v.NewPipeline()
+	d.Batch = v.NewBatch()
+
+	var testArgs []string
+	if len(args) > 0 {
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "-test.") {
+				arg = "-test." + strings.TrimLeft(arg, "-")
+			}
+			testArgs = append(testArgs, arg)
 		}
-	// TODO(zchee): Now support function only.
-	default:
-		return nil, errors.New("Too many arguments")
+	} else if fn, err := d.enclosingTest(v, eval); err == nil {
+		testArgs = fn.Flags("test.")
 	}
 
-	return bpInfo, nil
+	cfg := Config{
+		path: ".",
+		dir:  eval.Dir,
+		args: testArgs,
+	}
+	go d.start("test", cfg, &delveEval{Cwd: eval.Cwd, Dir: eval.Dir})
 }
 
-// breakpoint sets a breakpoint, and sets marker to Nvim sign area.
-// Note that 'break' name is reverved Go language spec.
-func (d *Delve) breakpoint(v *nvim.Nvim, args []string, eval *breakpointEval) error {
-	bpInfo, err := d.parseArgs(v, args, eval)
+// enclosingTest returns the test function of the current cursor.
+func (d *Delve) enclosingTest(v *nvim.Nvim, eval *testEval) (*gotest.Func, error) {
+	b, err := v.CurrentBuffer()
 	if err != nil {
-		nvimutil.ErrorWrap(v, errors.WithStack(err))
+		return nil, errors.WithStack(err)
 	}
-
-	if d.bpSign == nil {
-		d.bpSign = make(map[int]*nvimutil.Sign)
+	buf, err := v.BufferLines(b, 0, -1, true)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
-	bp, err := d.client.CreateBreakpoint(bpInfo) // *delveapi.Breakpoint
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, eval.File, nvimutil.ToByteSlice(buf), 0)
 	if err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		return nil, errors.WithStack(err)
+	}
+	pos := fset.File(f.Pos()).Pos(eval.Offset)
+	path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+
+	return gotest.Enclosing(path)
+}
+
+// ----------------------------------------------------------------------------
+// trace
+
+// cmdTrace setup the tracing of the functions which matches the args[0] regexp.
+func (d *Delve) cmdTrace(v *nvim.Nvim, args []string, eval *delveEval) {
+	d.Pipeline = v.NewPipeline()
+	d.Batch = v.NewBatch()
+
+	if len(args) == 0 {
+		nvimutil.ErrorWrap(v, errors.New("DlvTrace requires the function regexp"))
+		return
 	}
+	cfg := Config{
+		path:    d.findRootDir(eval),
+		pattern: args[0],
+		flags:   args[1:],
+	}
+	go d.start("trace", cfg, eval)
+}
 
-	d.bpSign[bp.ID], err = nvimutil.NewSign(v, "delve_bp", nvimutil.BreakpointSymbol, "delveBreakpointSign", "") // *nvim.Sign
+// trace sets the tracepoints to the functions which matches the pattern, and
+// prints the each tracepoint hits to the terminal buffer until the program
+// stops at the other than tracepoint or exits.
+func (d *Delve) trace(v *nvim.Nvim, pattern, dir string) error {
+	funcs, err := d.client.ListFunctions(pattern)
 	if err != nil {
 		return nvimutil.ErrorWrap(v, errors.WithStack(err))
 	}
-	d.bpSign[bp.ID].Place(v, bp.ID, bp.Line, bp.File, false)
+	if len(funcs) == 0 {
+		return nvimutil.ErrorWrap(v, errors.Errorf("no functions matches %s", pattern))
+	}
 
-	filename := pathutil.ShortFilePath(bp.File, filepath.Dir(eval.File))
-	msg := fmt.Sprintf("Breakpoint %d set at %#v for %s() %s:%d", bp.ID, bp.Addr, bp.FunctionName, filename, bp.Line)
-	if err := d.printTerminal("break "+bp.FunctionName, nvimutil.StrToByteSlice(msg)); err != nil {
+	for _, fn := range funcs {
+		_, err := d.client.CreateBreakpoint(&delveapi.Breakpoint{
+			FunctionName: fn,
+			Tracepoint:   true,
+			Line:         -1,
+			LoadArgs:     &delveterm.ShortLoadConfig,
+		})
+		if err != nil {
+			return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		}
+	}
+	if err := d.printTerminal("trace "+pattern, []byte(fmt.Sprintf("%d tracepoints set", len(funcs)))); err != nil {
 		return nvimutil.ErrorWrap(v, errors.WithStack(err))
 	}
 
+	if err := d.beginExec(v, "trace"); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	defer d.endExec(v)
+
+	for state := range d.client.Continue() {
+		if state.Err != nil {
+			return d.printTerminal("", []byte(state.Err.Error()))
+		}
+		if err := d.printTracepoints(state, dir); err != nil {
+			return nvimutil.ErrorWrap(v, err)
+		}
+	}
+
 	return nil
 }
 
@@ -281,88 +523,284 @@ func (d *Delve) cmdContinue(v *nvim.Nvim, args []string, eval *continueEval) {
 // sign marker to current stopping position.
 // Note that 'continue' name is reverved Go language spec.
 func (d *Delve) cont(v *nvim.Nvim, args []string, eval *continueEval) error {
-	stateCh := d.client.Continue()
-	state := <-stateCh
-	if err := d.printServerStderr(); err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
-	}
-	if state == nil || state.Exited {
-		return nvimutil.ErrorWrap(v, errors.WithStack(state.Err))
+	if err := d.beginExec(v, "continue"); err != nil {
+		return nvimutil.ErrorWrap(v, err)
 	}
+	state := d.continueState(v, eval.Dir)
+	d.endExec(v)
+	return d.updateState(v, "continue", eval.Dir, state, nil)
+}
 
-	cThread := state.CurrentThread
+// continueState continues the program until stops at the breakpoint which
+// satisfies the hit count condition, or exits. The tracepoint hits are printed
+// to the terminal buffer while continue.
+func (d *Delve) continueState(v *nvim.Nvim, dir string) *delveapi.DebuggerState {
+	for {
+		var state *delveapi.DebuggerState
+		for state = range d.client.Continue() {
+			if err := d.printTracepoints(state, dir); err != nil {
+				nvimutil.ErrorWrap(v, err)
+			}
+		}
 
-	go func() {
-		goroutines, err := d.client.ListGoroutines()
-		if err != nil {
-			nvimutil.ErrorWrap(v, errors.WithStack(err))
-			return
+		if state == nil || state.Exited || state.Err != nil || d.hitCondSatisfied(state.CurrentThread) {
+			return state
 		}
-		d.printContext(eval.Dir, cThread, goroutines)
-	}()
+	}
+}
 
-	go d.pcSign.Place(v, cThread.ID, cThread.Line, cThread.File, true)
+// hitCondSatisfied reports whether the stopped breakpoint of thread satisfies
+// the hit count condition. Reports true if the thread is not stopped at the
+// breakpoint or the breakpoint has no hit count condition.
+func (d *Delve) hitCondSatisfied(thread *delveapi.Thread) bool {
+	if thread == nil || thread.Breakpoint == nil {
+		return true
+	}
 
-	go func() {
-		if err := v.SetWindowCursor(d.cw, [2]int{cThread.Line, 0}); err != nil {
-			nvimutil.ErrorWrap(v, errors.WithStack(err))
-			return
+	d.bpMu.Lock()
+	cond, ok := d.bpHitConds[thread.Breakpoint.ID]
+	d.bpMu.Unlock()
+	if !ok {
+		return true
+	}
+
+	satisfied, err := hitCondSatisfied(cond, thread.Breakpoint.TotalHitCount)
+	return err != nil || satisfied
+}
+
+// printTracepoints prints the tracepoint hits of state to the terminal buffer.
+func (d *Delve) printTracepoints(state *delveapi.DebuggerState, dir string) error {
+	for _, th := range state.Threads {
+		if th.Breakpoint == nil || !th.Breakpoint.Tracepoint || !d.hitCondSatisfied(th) {
+			continue
 		}
-		if err := v.Command("silent normal zz"); err != nil {
-			nvimutil.ErrorWrap(v, errors.WithStack(err))
-			return
+		fnName := th.Breakpoint.FunctionName
+		if th.Function != nil {
+			fnName = th.Function.Name
 		}
-	}()
+		var fnArgs []string
+		if th.BreakpointInfo != nil {
+			for _, arg := range th.BreakpointInfo.Arguments {
+				fnArgs = append(fnArgs, arg.SinglelineString())
+			}
+		}
+		msg := fmt.Sprintf("> goroutine(%d): %s(%s) %s:%d%s",
+			th.GoroutineID,
+			fnName,
+			strings.Join(fnArgs, ", "),
+			pathutil.ShortFilePath(th.File, dir),
+			th.Line,
+			breakpointVariables(th.BreakpointInfo, dir))
+		if err := d.printTerminal("", []byte(msg)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
 
-	var msg []byte
-	if hitCount, ok := cThread.Breakpoint.HitCount[strconv.Itoa(cThread.GoroutineID)]; ok {
-		msg = []byte(
-			fmt.Sprintf("> %s() %s:%d (hits goroutine(%d):%d total:%d) (PC: %#v)",
-				cThread.Function.Name,
-				pathutil.ShortFilePath(cThread.File, eval.Dir),
-				cThread.Line,
-				cThread.GoroutineID,
-				hitCount,
-				cThread.Breakpoint.TotalHitCount,
-				cThread.PC))
-	} else {
-		msg = []byte(
-			fmt.Sprintf("> %s() %s:%d (hits total:%d) (PC: %#v)",
-				cThread.Function.Name,
-				pathutil.ShortFilePath(cThread.File, eval.Dir),
-				cThread.Line,
-				cThread.Breakpoint.TotalHitCount,
-				cThread.PC))
+	return nil
+}
+
+// breakpointVariables returns the evaluated breakpoint variables lines of info,
+// and the goroutine, locals and stacktrace loaded by the on command.
+// The arguments are not included, because the tracepoint prints them as the
+// function call.
+func breakpointVariables(info *delveapi.BreakpointInfo, dir string) string {
+	if info == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if g := info.Goroutine; g != nil {
+		fmt.Fprintf(&buf, "\n\tgoroutine(%d): %s", g.ID, locationString(g.UserCurrentLoc, dir))
+	}
+	for _, v := range info.Variables {
+		fmt.Fprintf(&buf, "\n\t%s: %s", v.Name, v.SinglelineString())
+	}
+	for _, v := range info.Locals {
+		fmt.Fprintf(&buf, "\n\tlocal %s: %s", v.Name, v.SinglelineString())
 	}
-	return d.printTerminal("continue", msg)
+	if len(info.Stacktrace) > 0 {
+		buf.WriteString("\n\tStack:")
+		for i, f := range info.Stacktrace {
+			fmt.Fprintf(&buf, "\n\t%4d  %s", i, locationString(f.Location, dir))
+		}
+	}
+	return buf.String()
+}
+
+// breakpointArguments returns the evaluated breakpoint arguments lines of info.
+func breakpointArguments(info *delveapi.BreakpointInfo) string {
+	if info == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	for _, v := range info.Arguments {
+		fmt.Fprintf(&buf, "\n\targ %s: %s", v.Name, v.SinglelineString())
+	}
+	return buf.String()
 }
 
 // ----------------------------------------------------------------------------
-// next
+// next, step, stepout and step-instruction
 
-// breakpointEval represent a breakpoint commands Eval args.
-type nextEval struct {
+// stepEval represent a stepping commands Eval args.
+type stepEval struct {
 	Dir string `msgpack:",array"`
 }
 
-func (d *Delve) cmdNext(v *nvim.Nvim, eval *nextEval) {
+func (d *Delve) cmdNext(v *nvim.Nvim, eval *stepEval) {
 	go d.next(v, eval)
 }
 
 // next sends the 'next' signals to the delve headless server, and update sign
 // marker to current stopping position.
-func (d *Delve) next(v *nvim.Nvim, eval *nextEval) error {
+func (d *Delve) next(v *nvim.Nvim, eval *stepEval) error {
+	if err := d.beginExec(v, "next"); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
 	state, err := d.client.Next()
-	// prints server stderr before the prints the error messages
-	if err := d.printServerStderr(); err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	d.endExec(v)
+	return d.updateState(v, "next", eval.Dir, state, err)
+}
+
+func (d *Delve) cmdStep(v *nvim.Nvim, eval *stepEval) {
+	go d.step(v, eval)
+}
+
+// step sends the 'step' signals to the delve headless server, which steps
+// into the function call, and update sign marker to current stopping position.
+func (d *Delve) step(v *nvim.Nvim, eval *stepEval) error {
+	if err := d.beginExec(v, "step"); err != nil {
+		return nvimutil.ErrorWrap(v, err)
 	}
-	// handle the d.client.Next() error
+	state, err := d.client.Step()
+	d.endExec(v)
+	return d.updateState(v, "step", eval.Dir, state, err)
+}
+
+func (d *Delve) cmdStepOut(v *nvim.Nvim, eval *stepEval) {
+	go d.stepOut(v, eval)
+}
+
+// stepOut sends the 'stepout' signals to the delve headless server, which
+// steps out of the current function, and update sign marker to current
+// stopping position.
+func (d *Delve) stepOut(v *nvim.Nvim, eval *stepEval) error {
+	if err := d.beginExec(v, "stepout"); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	state, err := d.client.StepOut()
+	d.endExec(v)
+	return d.updateState(v, "stepout", eval.Dir, state, err)
+}
+
+func (d *Delve) cmdStepInstruction(v *nvim.Nvim, eval *stepEval) {
+	go d.stepInstruction(v, eval)
+}
+
+// stepInstruction sends the 'step-instruction' signals to the delve headless
+// server, which steps a single cpu instruction, and update sign marker to
+// current stopping position.
+func (d *Delve) stepInstruction(v *nvim.Nvim, eval *stepEval) error {
+	if err := d.beginExec(v, "step-instruction"); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	state, err := d.client.StepInstruction()
+	d.endExec(v)
+	return d.updateState(v, "step-instruction", eval.Dir, state, err)
+}
+
+// ----------------------------------------------------------------------------
+// run to cursor
+
+// runToCursorEval represent a DlvRunToCursor command Eval args.
+type runToCursorEval struct {
+	Dir  string `msgpack:",array"`
+	File string
+	Line int
+}
+
+func (d *Delve) cmdRunToCursor(v *nvim.Nvim, eval *runToCursorEval) {
+	go d.runToCursor(v, eval)
+}
+
+// runToCursor sets the temporary breakpoint at the cursor line and continues
+// the program. The temporary breakpoint is cleared after the program stopped.
+func (d *Delve) runToCursor(v *nvim.Nvim, eval *runToCursorEval) error {
+	if err := d.beginExec(v, "continue"); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	bps, err := d.client.ListBreakpoints()
 	if err != nil {
+		d.endExec(v)
 		return nvimutil.ErrorWrap(v, errors.WithStack(err))
 	}
 
+	// uses the existing breakpoint as is if already set to the cursor line
+	temporary := true
+	for _, bp := range bps {
+		if bp.File == eval.File && bp.Line == eval.Line {
+			temporary = false
+			break
+		}
+	}
+
+	var bp *delveapi.Breakpoint
+	if temporary {
+		bp, err = d.client.CreateBreakpoint(&delveapi.Breakpoint{File: eval.File, Line: eval.Line})
+		if err != nil {
+			d.endExec(v)
+			return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		}
+	}
+
+	state := d.continueState(v, eval.Dir)
+	d.endExec(v)
+
+	if temporary && (state == nil || !state.Exited) {
+		if _, err := d.client.ClearBreakpoint(bp.ID); err != nil {
+			return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		}
+	}
+
+	return d.updateState(v, "continue", eval.Dir, state, nil)
+}
+
+// ----------------------------------------------------------------------------
+// state update
+
+// updateState updates the context buffer, pc sign and the cursor to the
+// stopped position of state, and prints the stopped location to the terminal
+// buffer. The err is the error of the execution control command.
+func (d *Delve) updateState(v *nvim.Nvim, cmd, dir string, state *delveapi.DebuggerState, err error) error {
+	cThread, err := d.showState(v, dir, state, err)
+	// the exited program has no current thread
+	if err != nil || cThread == nil {
+		return err
+	}
+
+	return d.printTerminal(cmd, stoppedMessage(cThread, dir))
+}
+
+// showState updates the debug buffers, pc sign and the cursor to the stopped
+// position of state, and returns the current thread. Returns the nil thread
+// if the program exited.
+func (d *Delve) showState(v *nvim.Nvim, dir string, state *delveapi.DebuggerState, err error) (*delveapi.Thread, error) {
+	// handle the execution control command error
+	if err != nil {
+		return nil, nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+	if state == nil {
+		return nil, nvimutil.ErrorWrap(v, errors.New("could not get the debugger state"))
+	}
+	if state.Exited || state.Err != nil {
+		return nil, nvimutil.ErrorWrap(v, errors.WithStack(state.Err))
+	}
+
 	cThread := state.CurrentThread
+	if cThread == nil {
+		return nil, nvimutil.ErrorWrap(v, errors.New("could not get the current thread"))
+	}
+	d.resetScope(cThread)
 
 	go func() {
 		goroutines, err := d.client.ListGoroutines()
@@ -370,7 +808,25 @@ func (d *Delve) next(v *nvim.Nvim, eval *nextEval) error {
 			nvimutil.ErrorWrap(v, errors.WithStack(err))
 			return
 		}
-		d.printContext(eval.Dir, cThread, goroutines)
+		d.printContext(dir, cThread, goroutines)
+	}()
+
+	go func() {
+		if err := d.printWatch(); err != nil {
+			nvimutil.ErrorWrap(v, err)
+		}
+	}()
+
+	go func() {
+		if err := d.printThreads(dir); err != nil {
+			nvimutil.ErrorWrap(v, err)
+		}
+	}()
+
+	go func() {
+		if err := d.printDisassembly(cThread.PC, dir); err != nil {
+			nvimutil.ErrorWrap(v, err)
+		}
 	}()
 
 	go d.pcSign.Place(v, cThread.ID, cThread.Line, cThread.File, true)
@@ -386,14 +842,50 @@ func (d *Delve) next(v *nvim.Nvim, eval *nextEval) error {
 		}
 	}()
 
-	msg := []byte(
-		fmt.Sprintf("> %s() %s:%d goroutine(%d) (PC: %d)",
-			cThread.Function.Name,
-			pathutil.ShortFilePath(cThread.File, eval.Dir),
-			cThread.Line,
-			cThread.GoroutineID,
-			cThread.PC))
-	return d.printTerminal("next", msg)
+	return cThread, nil
+}
+
+// stoppedMessage returns the stopped location message of the thread.
+// Includes the hit counts if the thread stopped at the breakpoint.
+func stoppedMessage(thread *delveapi.Thread, dir string) []byte {
+	var fn string
+	if thread.Function != nil {
+		fn = thread.Function.Name
+	}
+	fname := pathutil.ShortFilePath(thread.File, dir)
+
+	bp := thread.Breakpoint
+	vars := breakpointArguments(thread.BreakpointInfo) + breakpointVariables(thread.BreakpointInfo, dir)
+	if bp == nil {
+		return []byte(
+			fmt.Sprintf("> %s() %s:%d goroutine(%d) (PC: %#v)",
+				fn,
+				fname,
+				thread.Line,
+				thread.GoroutineID,
+				thread.PC))
+	}
+
+	if hitCount, ok := bp.HitCount[strconv.Itoa(thread.GoroutineID)]; ok {
+		return []byte(
+			fmt.Sprintf("> %s() %s:%d (hits goroutine(%d):%d total:%d) (PC: %#v)%s",
+				fn,
+				fname,
+				thread.Line,
+				thread.GoroutineID,
+				hitCount,
+				bp.TotalHitCount,
+				thread.PC,
+				vars))
+	}
+	return []byte(
+		fmt.Sprintf("> %s() %s:%d (hits total:%d) (PC: %#v)%s",
+			fn,
+			fname,
+			thread.Line,
+			bp.TotalHitCount,
+			thread.PC,
+			vars))
 }
 
 // ----------------------------------------------------------------------------
@@ -404,7 +896,11 @@ func (d *Delve) cmdRestart(v *nvim.Nvim) {
 }
 
 func (d *Delve) restart(v *nvim.Nvim) error {
+	if err := d.beginExec(v, "restart"); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
 	discarded, err := d.client.Restart()
+	d.endExec(v)
 	if err != nil {
 		return nvimutil.ErrorWrap(v, errors.WithStack(err))
 	}
@@ -414,11 +910,25 @@ func (d *Delve) restart(v *nvim.Nvim) error {
 	d.processPid = d.client.ProcessPid()
 	buf.WriteString(fmt.Sprintf("Process restarted with PID %d\n", d.processPid))
 
+	d.bpMu.Lock()
 	for i := range discarded {
-		buf.WriteString(fmt.Sprintf("Discarded %s at %s: %v\n", discarded[i].Breakpoint, discarded[i].Breakpoint, discarded[i].Reason))
+		bp := discarded[i].Breakpoint
+		buf.WriteString(fmt.Sprintf("Discarded %s() %s:%d: %v\n", bp.FunctionName, bp.File, bp.Line, discarded[i].Reason))
+		for id, bpID := range d.bpIDs {
+			if bpID == bp.ID {
+				delete(d.bpIDs, id)
+			}
+		}
+		delete(d.bpHitConds, bp.ID)
+	}
+	d.bpMu.Unlock()
+
+	if err := d.printTerminal("restart", buf.Bytes()); err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
 	}
 
-	return d.printTerminal("restart", buf.Bytes())
+	// re-applies the discarded breakpoints in case of the lines were moved
+	return nvimutil.ErrorWrap(v, d.applyBreakpoints(v))
 }
 
 // ----------------------------------------------------------------------------
@@ -437,56 +947,6 @@ func (d *Delve) state(v *nvim.Nvim) error {
 	return nil
 }
 
-// ----------------------------------------------------------------------------
-// stdin
-
-func (d *Delve) cmdStdin(v *nvim.Nvim) {
-	go d.stdin(v)
-}
-
-// stdin sends the users input command to the internal delve terminal.
-// vim input() function args:
-//  input({prompt} [, {text} [, {completion}]])
-// More information of input() funciton and word completion are
-//  :help input()
-//  :help command-completion-custom
-func (d *Delve) stdin(v *nvim.Nvim) error {
-	var stdin interface{}
-	err := v.Call("input", &stdin, "(dlv) ", "")
-	if err != nil {
-		return nil
-	}
-
-	// Create the connected pair of *os.Files and replace os.Stdout.
-	// delve terminal package return to stdout only.
-	r, w, _ := os.Pipe() // *os.File
-	saveStdout := os.Stdout
-	os.Stdout = w
-
-	cmd := strings.SplitN(stdin.(string), " ", 2)
-	var args string
-	if len(cmd) == 2 {
-		args = cmd[1]
-	}
-
-	err = d.debugger.Call(cmd[0], args, d.term)
-	if err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
-	}
-
-	// Close the w file and restore os.Stdout to original.
-	w.Close()
-	os.Stdout = saveStdout
-
-	// Read all the lines of r file.
-	out, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
-	}
-
-	return d.printTerminal(stdin.(string), out)
-}
-
 // ----------------------------------------------------------------------------
 // command-line completion
 
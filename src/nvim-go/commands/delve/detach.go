@@ -12,23 +12,72 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (d *Delve) cmdDetach(v *nvim.Nvim) {
-	go d.detach(v)
+func (d *Delve) cmdDetach(v *nvim.Nvim, bang bool) {
+	go d.detach(v, bang)
 }
 
-func (d *Delve) detach(v *nvim.Nvim) error {
+func (d *Delve) cmdVimLeavePre(v *nvim.Nvim) {
+	go d.detach(v, false)
+}
+
+// detach exits the debugger and kills the server.
+// If keep is true, disconnects the client and keeps the server running for
+// the DlvReconnect.
+func (d *Delve) detach(v *nvim.Nvim, keep bool) error {
+	if keep {
+		return d.disconnect(v)
+	}
+
 	defer d.kill()
 	if d.processPid != 0 {
-		err := d.client.Detach(true)
+		// Does not kill the attached process which is not launched by delve,
+		// and the debuggee of the server which is not launched by the plugin
+		err := d.client.Detach(d.launched && d.mode != "attach")
 		if err != nil {
 			return nvimutil.ErrorWrap(d.Nvim, errors.WithStack(err))
 		}
 		log.Printf("Detached delve client\n")
+
+		if err := removeSession(sessionFile(d.project)); err != nil {
+			return nvimutil.ErrorWrap(d.Nvim, err)
+		}
 	}
+	d.stopTail()
+	d.reset(v)
 
 	return nil
 }
 
+// reset resets the states of the debugging session.
+func (d *Delve) reset(v *nvim.Nvim) {
+	if d.client != nil {
+		d.client.Close()
+		d.client = nil
+	}
+	d.launched = false
+	d.processPid = 0
+	d.output.stop()
+
+	d.execMu.Lock()
+	d.executing = ""
+	d.execMu.Unlock()
+	d.setStatus(v, "")
+
+	d.bpMu.Lock()
+	d.bpIDs = nil
+	d.bpHitConds = nil
+	d.bpMu.Unlock()
+}
+
+// abort kills the server launched by the failed start, and removes the debug
+// buffers, session and client of it.
+func (d *Delve) abort() {
+	d.kill()
+	removeSession(sessionFile(d.project))
+	d.deleteDebugBuffer()
+	d.reset(d.Nvim)
+}
+
 func (d *Delve) kill() error {
 	if d.server != nil {
 		err := d.server.Process.Kill()
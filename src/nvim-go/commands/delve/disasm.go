@@ -0,0 +1,92 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/pkg/errors"
+)
+
+// Disassembly define disassembly buffer name.
+const Disassembly nvimutil.BufferName = "disassembly"
+
+// printDisassembly prints the disassembly of the function which contains pc
+// to the disassembly buffer, and moves the cursor to the pc line.
+// The source lines are interleaved before the instructions of the line.
+func (d *Delve) printDisassembly(pc uint64, cwd string) error {
+	buf := d.buffer[Disassembly]
+	if buf == nil {
+		return nil
+	}
+
+	insts, err := d.client.DisassemblePC(d.evalScope(), pc, delveapi.IntelFlavour)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var lines [][]byte
+	var pcLine int
+	var file string
+	var line int
+	sources := make(map[string][][]byte)
+	for i, inst := range insts {
+		if i == 0 && inst.Loc.Function != nil {
+			lines = append(lines, []byte(inst.Loc.Function.Name+"()"))
+		}
+		if inst.Loc.File != file || inst.Loc.Line != line {
+			file, line = inst.Loc.File, inst.Loc.Line
+			lines = append(lines, sourceLine(sources, file, line, cwd))
+		}
+
+		mark := "  "
+		switch {
+		case inst.Loc.PC == pc:
+			mark = "=>"
+			pcLine = len(lines) + 1
+		case inst.Breakpoint:
+			mark = " *"
+		}
+		lines = append(lines, []byte(fmt.Sprintf("%s\t%#x\t%s", mark, inst.Loc.PC, inst.Text)))
+	}
+
+	d.Nvim.SetBufferOption(buf.Buffer(), "modifiable", true)
+	defer d.Nvim.SetBufferOption(buf.Buffer(), "modifiable", false)
+
+	if err := d.Nvim.SetBufferLines(buf.Buffer(), 0, -1, true, lines); err != nil {
+		return errors.WithStack(err)
+	}
+	if pcLine == 0 {
+		return nil
+	}
+
+	return errors.WithStack(d.Nvim.SetWindowCursor(buf.Window, [2]int{pcLine, 0}))
+}
+
+// sourceLine returns the source line of the file:line for the disassembly
+// buffer. The read file contents are cached to sources.
+func sourceLine(sources map[string][][]byte, file string, line int, cwd string) []byte {
+	src, ok := sources[file]
+	if !ok {
+		// the runtime sources may not exist on the machine, such as the core dump
+		data, err := ioutil.ReadFile(file)
+		if err == nil {
+			src = bytes.Split(data, []byte{'\n'})
+		}
+		sources[file] = src
+	}
+
+	loc := fmt.Sprintf("%s:%d:", pathutil.ShortFilePath(file, cwd), line)
+	if line < 1 || line > len(src) {
+		return []byte(loc)
+	}
+	return append([]byte(loc+"\t"), bytes.TrimSpace(src[line-1])...)
+}
@@ -0,0 +1,167 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// Eval define eval preview buffer name.
+const Eval nvimutil.BufferName = "eval"
+
+// ----------------------------------------------------------------------------
+// eval
+
+type evalEval struct {
+	Cword string `msgpack:",array"`
+	// Start and End positions of the last visual selection, which are the
+	// getpos() results of [bufnum, lnum, col, off].
+	Start [4]int
+	End   [4]int
+}
+
+func (d *Delve) cmdEval(v *nvim.Nvim, args []string, count int, eval *evalEval) {
+	go d.eval(v, args, count, eval)
+}
+
+// eval evaluates the expression on the selected goroutine and frame scope.
+// The expression is args, or the visual selection if the command called with
+// the range, or the word under the cursor.
+// The result is shown in the echo area, or in the preview window if the result
+// has the children.
+func (d *Delve) eval(v *nvim.Nvim, args []string, count int, eval *evalEval) error {
+	if !d.running() {
+		return nvimutil.ErrorWrap(v, errors.New("the debugging session is not running"))
+	}
+	if d.busy() {
+		return nvimutil.ErrorWrap(v, errors.New("the program is running. Use DlvHalt to stop the program"))
+	}
+
+	expr := strings.Join(args, " ")
+	if expr == "" && count > 0 {
+		sel, err := visualSelection(v, eval.Start, eval.End)
+		if err != nil {
+			return nvimutil.ErrorWrap(v, err)
+		}
+		expr = sel
+	}
+	if expr == "" {
+		expr = eval.Cword
+	}
+	if expr == "" {
+		return nvimutil.ErrorWrap(v, errors.New("empty expression"))
+	}
+
+	val, err := d.evalVariable(expr)
+	if err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+	n := &varNode{Name: expr, Expr: expr, Var: val}
+	if !n.expandable() {
+		return v.WriteOut(strings.TrimSpace(n.line(0)) + "\n")
+	}
+
+	d.evalMu.Lock()
+	defer d.evalMu.Unlock()
+
+	d.evalTree = newVarTree()
+	section := &varSection{Title: "Evaluation", Nodes: []*varNode{n}}
+	d.evalTree.reset([]*varSection{section}, d.evalVariable)
+	if err := d.evalTree.expand(n, d.evalVariable); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	return nvimutil.ErrorWrap(v, d.renderEval(v))
+}
+
+// visualSelection returns the text of the visual selection between start and
+// end positions of the current buffer.
+func visualSelection(v *nvim.Nvim, start, end [4]int) (string, error) {
+	buf, err := v.CurrentBuffer()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	lines, err := v.BufferLines(buf, start[1]-1, end[1], true)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	// the col of the linewise visual mode is the large number
+	last := len(lines) - 1
+	if end[2] < len(lines[last]) {
+		lines[last] = lines[last][:end[2]]
+	}
+	if start[2] > 0 && start[2]-1 < len(lines[0]) {
+		lines[0] = lines[0][start[2]-1:]
+	}
+
+	return strings.TrimSpace(string(bytes.Join(lines, []byte{' '}))), nil
+}
+
+// renderEval renders the eval tree to the eval preview buffer, and creates the
+// buffer if not exists.
+// The caller must be hold the d.evalMu lock.
+func (d *Delve) renderEval(v *nvim.Nvim) error {
+	if d.evalPreview == nil || !nvimutil.IsBufferValid(v, d.evalPreview.Buffer()) {
+		cw, err := v.CurrentWindow()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer v.SetCurrentWindow(cw)
+
+		// close the other preview window before open the eval preview window
+		if err := v.Command("silent pclose"); err != nil {
+			return errors.WithStack(err)
+		}
+		d.evalPreview = nvimutil.NewBuffer(v)
+		if err := d.evalPreview.Create(string(Eval), nvimutil.FiletypeDelve, "silent botright 10 split", d.setTerminalOption()); err != nil {
+			return errors.WithStack(err)
+		}
+		// the eval buffer window is closed by the :pclose
+		if err := v.SetWindowOption(d.evalPreview.Window, "previewwindow", true); err != nil {
+			return errors.WithStack(err)
+		}
+		// <CR> and o expands or collapses the variable of the cursor line
+		nnoremap := map[string]string{
+			"<CR>": fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvToggleEval', line('.'))<CR>", config.ChannelID),
+			"o":    fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvToggleEval', line('.'))<CR>", config.ChannelID),
+			"q":    ":<C-u>pclose<CR>",
+		}
+		if err := d.evalPreview.SetLocalMapping(nvimutil.NoremapNormal, nnoremap); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	defer nvimutil.Modifiable(v, d.evalPreview.Buffer())()
+
+	return errors.WithStack(v.SetBufferLines(d.evalPreview.Buffer(), 0, -1, true, d.evalTree.render()))
+}
+
+// toggleEval expands or collapses the variable of the eval preview buffer line.
+func (d *Delve) toggleEval(v *nvim.Nvim, line int) error {
+	d.evalMu.Lock()
+	defer d.evalMu.Unlock()
+
+	if d.evalTree == nil {
+		return nil
+	}
+	toggled, err := d.evalTree.toggle(line-1, d.evalVariable)
+	if err != nil || !toggled {
+		return errors.WithStack(err)
+	}
+
+	return d.renderEval(v)
+}
@@ -0,0 +1,94 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// statusVar global variable name of the debugging session status, which
+// useful for the statusline.
+const statusVar = "go#delve#status"
+
+const (
+	statusRunning = "running"
+	statusStopped = "stopped"
+)
+
+// beginExec marks the session as busy by the execution control cmd, and sets
+// the running status. Returns the error if the other execution control command
+// is in progress, because the delve server blocks the all requests until the
+// program stops.
+func (d *Delve) beginExec(v *nvim.Nvim, cmd string) error {
+	if d.readOnly() {
+		return errors.Errorf("%s is not available on the core dump", cmd)
+	}
+
+	d.execMu.Lock()
+	if d.executing != "" {
+		executing := d.executing
+		d.execMu.Unlock()
+		return errors.Errorf("%s is in progress. Use DlvHalt to stop the program", executing)
+	}
+	d.executing = cmd
+	d.execMu.Unlock()
+
+	return d.setStatus(v, statusRunning)
+}
+
+// endExec clears the busy mark of the session, and sets the stopped status.
+func (d *Delve) endExec(v *nvim.Nvim) error {
+	d.execMu.Lock()
+	d.executing = ""
+	d.execMu.Unlock()
+
+	return d.setStatus(v, statusStopped)
+}
+
+// busy reports whether the execution control command is in progress.
+func (d *Delve) busy() bool {
+	d.execMu.Lock()
+	defer d.execMu.Unlock()
+
+	return d.executing != ""
+}
+
+// readOnly reports whether the session is the post-mortem debugging of the
+// core dump, which can not control the execution and set the breakpoints.
+func (d *Delve) readOnly() bool {
+	return d.mode == "core"
+}
+
+// setStatus sets the g:go#delve#status variable and redraws the statusline.
+func (d *Delve) setStatus(v *nvim.Nvim, status string) error {
+	if err := v.SetVar(statusVar, status); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(v.Command("redrawstatus!"))
+}
+
+// ----------------------------------------------------------------------------
+// halt
+
+func (d *Delve) cmdHalt(v *nvim.Nvim) {
+	go d.halt(v)
+}
+
+// halt stops the running program. The stopped location is printed by the
+// in progress execution control command.
+func (d *Delve) halt(v *nvim.Nvim) error {
+	if !d.running() {
+		return nvimutil.ErrorWrap(v, errors.New("the debugging session is not running"))
+	}
+	if !d.busy() {
+		return nvimutil.ErrorWrap(v, errors.New("the program is not running"))
+	}
+
+	_, err := d.client.Halt()
+	return nvimutil.ErrorWrap(v, errors.WithStack(err))
+}
@@ -0,0 +1,227 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"nvim-go/internal/storage"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// Output define the debuggee program output buffer name.
+const Output nvimutil.BufferName = "output"
+
+// outputFile returns the output log file path of the project. The server
+// writes the stdout and stderr to the file instead of the pipe, so the server
+// keeps running after the client detached or Neovim exited.
+func outputFile(project string) string {
+	return storage.DataPath("delve", project, "output.log")
+}
+
+// outputWriter implements the io.Writer interface, which streams the server
+// stdout and stderr to the output buffer incrementally.
+// The written data is not streamed until the delve client is connected, to
+// avoid the server setup logs. The setup logs are kept for the report of the
+// server startup errors such as the build errors.
+type outputWriter struct {
+	v *nvim.Nvim
+
+	mu    sync.Mutex
+	buf   *nvimutil.Buffer
+	ready bool
+	// pending written data which is not written to the output buffer, such as
+	// the setup logs before the streaming starts.
+	pending bytes.Buffer
+	// lines number of lines written to the output buffer.
+	lines int
+	// partial whether the last written line is not terminated by the newline.
+	partial bool
+}
+
+// setBuffer sets the output buffer, and writes the pending data if the
+// streaming is started.
+func (w *outputWriter) setBuffer(buf *nvimutil.Buffer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = buf
+	if w.ready && w.pending.Len() > 0 {
+		w.write(w.pending.Bytes())
+		w.pending.Reset()
+	}
+}
+
+// start starts the streaming to the output buffer, and discards the setup logs.
+func (w *outputWriter) start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.ready = true
+	w.pending.Reset()
+	w.lines = 0
+	w.partial = false
+}
+
+// stop stops the streaming, and keeps the written data after that as the
+// setup logs.
+func (w *outputWriter) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.ready = false
+	w.pending.Reset()
+}
+
+// setupOutput returns the written data before the streaming starts.
+func (w *outputWriter) setupOutput() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]byte{}, w.pending.Bytes()...)
+}
+
+// Write appends p to the output buffer. The unterminated last line of the
+// output buffer is concatenated with the first line of p.
+// Write never returns the error, because the output is best effort and the
+// error stops the copying of the output.
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.ready || w.buf == nil {
+		w.pending.Write(p)
+		return len(p), nil
+	}
+	w.write(p)
+
+	return len(p), nil
+}
+
+// write writes p to the output buffer.
+// The caller must be hold the w.mu lock.
+func (w *outputWriter) write(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	v, buf := w.v, w.buf
+
+	lines := bytes.Split(p, []byte{'\n'})
+	start := w.lines
+	if w.partial {
+		start--
+		last, err := v.BufferLines(buf.Buffer(), start, start+1, true)
+		if err != nil || len(last) == 0 {
+			log.Printf("outputWriter: %+v", errors.WithStack(err))
+			return
+		}
+		lines[0] = append(last[0], lines[0]...)
+	}
+	w.partial = len(lines[len(lines)-1]) > 0
+	if !w.partial {
+		lines = lines[:len(lines)-1]
+	}
+
+	defer nvimutil.Modifiable(v, buf.Buffer())()
+
+	if err := v.SetBufferLines(buf.Buffer(), start, -1, true, lines); err != nil {
+		log.Printf("outputWriter: %+v", errors.WithStack(err))
+		return
+	}
+	w.lines = start + len(lines)
+
+	// follows the output like the "tail -f"
+	v.SetWindowCursor(buf.Window, [2]int{w.lines, 0})
+}
+
+// ----------------------------------------------------------------------------
+// output tail
+
+// tailInterval polling interval of the output log file.
+const tailInterval = 100 * time.Millisecond
+
+// outputTail streams the appended data of the output log file to the
+// outputWriter, like the "tail -f".
+type outputTail struct {
+	f    *os.File
+	w    io.Writer
+	mu   sync.Mutex
+	done chan struct{}
+}
+
+// tailOutput starts the streaming of fname from the beginning to w.
+func tailOutput(fname string, w io.Writer) (*outputTail, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	t := &outputTail{f: f, w: w, done: make(chan struct{})}
+	go t.run()
+
+	return t, nil
+}
+
+func (t *outputTail) run() {
+	ticker := time.NewTicker(tailInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			t.mu.Lock()
+			t.f.Close()
+			t.mu.Unlock()
+			return
+		case <-ticker.C:
+			t.read()
+		}
+	}
+}
+
+// read copies the appended data since the last read to w.
+func (t *outputTail) read() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := io.Copy(t.w, t.f); err != nil {
+		log.Printf("outputTail: %+v", errors.WithStack(err))
+	}
+}
+
+// close stops the streaming.
+func (t *outputTail) close() {
+	close(t.done)
+}
+
+// startTail starts the streaming of the output log file of the project, and
+// stops the previous streaming.
+func (d *Delve) startTail(project string) error {
+	d.stopTail()
+
+	t, err := tailOutput(outputFile(project), d.output)
+	if err != nil {
+		return err
+	}
+	d.outputTail = t
+
+	return nil
+}
+
+// stopTail stops the streaming of the output log file.
+func (d *Delve) stopTail() {
+	if d.outputTail != nil {
+		d.outputTail.close()
+		d.outputTail = nil
+	}
+}
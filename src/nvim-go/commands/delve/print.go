@@ -14,7 +14,6 @@ import (
 	"sort"
 
 	delveapi "github.com/derekparker/delve/service/api"
-	"github.com/neovim/go-client/nvim"
 	"github.com/pkg/errors"
 )
 
@@ -55,30 +54,38 @@ func (d *Delve) printTerminal(cmd string, message []byte) error {
 	return d.Nvim.SetWindowCursor(d.buffer[Terminal].Window, [2]int{len(afterBuf), 7})
 }
 
-// printServerStdout prints the server stdout results to terminal buffer.
-func (d *Delve) printServerStdout() error {
-	return d.printTerminal("", d.serverOut.Bytes())
-}
-
-// printServerStderr prints the server stderr results to terminal buffer.
-func (d *Delve) printServerStderr() error {
-	return d.printTerminal("", d.serverErr.Bytes())
-}
-
 // ----------------------------------------------------------------------------
 // context
 
+// printContext prints the stacktraces and the variable inspector tree to the
+// context buffer.
 func (d *Delve) printContext(cwd string, cThread *delveapi.Thread, goroutines []*delveapi.Goroutine) error {
+	stacks, hlLine, err := d.printStacktrace(cwd, cThread.Function, goroutines)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	d.varMu.Lock()
+	defer d.varMu.Unlock()
+
+	if err := d.loadVariables(); err != nil {
+		return errors.WithStack(err)
+	}
+	d.varStart = len(stacks)
+	lines := append(stacks, d.vars.render()...)
+
 	d.Nvim.SetBufferOption(d.buffer[Context].Buffer(), "modifiable", true)
 	defer d.Nvim.SetBufferOption(d.buffer[Context].Buffer(), "modifiable", false)
 
-	stackHeight, err := d.printStacktrace(cwd, cThread.Function, goroutines)
-	if err != nil {
+	if err := d.Nvim.SetBufferLines(d.buffer[Context].Buffer(), 0, -1, true, lines); err != nil {
 		return errors.WithStack(err)
 	}
 
-	if err := d.printLocals(cwd, d.Locals, stackHeight); err != nil {
-		return errors.WithStack(err)
+	if hlLine > 0 {
+		fade := nvimutil.NewFader(d.Nvim, d.buffer[Context].Buffer(), "delveFade", hlLine, hlLine, 3, -1, 80)
+		if err := fade.FadeOut(); err != nil {
+			return errors.WithStack(err)
+		}
 	}
 
 	return nil
@@ -96,21 +103,19 @@ func (a byGroutineID) Less(i, j int) bool { return a[i].ID < a[j].ID }
 
 const goroutineDepth = 20
 
-func (d *Delve) printStacktrace(cwd string, currentFunc *delveapi.Function, goroutines []*delveapi.Goroutine) (int, error) {
+// printStacktrace returns the stacktraces lines of the goroutines, and the
+// line number of the current goroutine for the highlight.
+func (d *Delve) printStacktrace(cwd string, currentFunc *delveapi.Function, goroutines []*delveapi.Goroutine) ([][]byte, int, error) {
 	sort.Sort(byGroutineID(goroutines))
 
-	var locals []delveapi.Variable
-	var fade *nvimutil.Fade
-
+	var hlLine int
 	stacksMsg := []byte("Stacktraces\n")
-	end, _ := d.Nvim.BufferLineCount(d.buffer[Context].Buffer())
 
 	for _, g := range goroutines {
 		// Get the each threads function name.
 		if g.CurrentLoc.Function.Name == currentFunc.Name {
 			stacksMsg = append(stacksMsg, byte('*'))
-			hlLine := len(nvimutil.ToBufferLines(stacksMsg))
-			fade = nvimutil.NewFader(d.Nvim, d.buffer[Context].Buffer(), "delveFade", hlLine, hlLine, 3, -1, 80)
+			hlLine = len(nvimutil.ToBufferLines(stacksMsg))
 		} else {
 			stacksMsg = append(stacksMsg, []byte(fmt.Sprintf("\t\u25B6 %s\n", g.CurrentLoc.Function.Name))...) // \u25B6: ▶
 			continue
@@ -120,9 +125,9 @@ func (d *Delve) printStacktrace(cwd string, currentFunc *delveapi.Function, goro
 
 		// Appends the stacktrace from each threads goroutine if valid goroutine ID.
 		if g.ID != 0 {
-			stacks, err := d.client.Stacktrace(g.ID, goroutineDepth, &delveapi.LoadConfig{FollowPointers: true}) // []delveapi.Stackframe
+			stacks, err := d.client.Stacktrace(g.ID, goroutineDepth, nil) // []delveapi.Stackframe
 			if err != nil {
-				return end, errors.WithStack(err)
+				return nil, 0, errors.WithStack(err)
 			}
 			for _, s := range stacks {
 				stacksMsg = append(stacksMsg, []byte(
@@ -130,70 +135,11 @@ func (d *Delve) printStacktrace(cwd string, currentFunc *delveapi.Function, goro
 						s.Function.Name,
 						pathutil.ShortFilePath(s.File, cwd),
 						s.Line))...)
-				locals = append(locals, s.Locals...)
 			}
 		}
 	}
 
-	stackData := nvimutil.ToBufferLines(stacksMsg)
-
-	// Saves and calculates the last stacktrace message height, and check the whether the first appned to buffer.
-	if end == 1 {
-		end = -1
-	} else {
-		end = len(stackData)
-	}
-
-	if err := d.Nvim.SetBufferLines(d.buffer[Context].Buffer(), 0, end, true, stackData); err != nil {
-		return end, errors.WithStack(err)
-	}
-
-	if err := fade.FadeOut(); err != nil {
-		return end, errors.WithStack(err)
-	}
-
-	// TODO(zchee): Comparison and cacheing.
-	// fmt.Sprintf("\t%s\n\t\taddr: %d\n\t\tonlyAddr: %t\n\t\ttype: %s\n\t\trealType: %s\n\t\tkind: %s\n\t\tvalue: %s\n\t\tlen: %d\n\t\tcap: %d\n\t\tunreadable: %s\n",
-	// 	l.Name,
-	// 	l.Addr,
-	// 	l.OnlyAddr,
-	// 	l.Type,
-	// 	l.RealType,
-	// 	l.Kind.String(),
-	// 	l.Value,
-	// 	l.Len,
-	// 	l.Cap,
-	// 	l.Unreadable))...)
-	d.Locals = locals
-
-	return end, nil
-}
-
-// ----------------------------------------------------------------------------
-// locals
-
-func (d *Delve) printLocals(cwd string, locals []delveapi.Variable, stackHeight int) error {
-	localsMsg := []byte("Local Variables\n")
-	for _, l := range locals {
-		localsMsg = append(localsMsg, []byte(fmt.Sprintf("\t\u25B6 %s %s\n", l.Name, l.Kind.String()))...) // \u25B6: ▶
-
-	}
-	if err := d.Nvim.SetBufferLines(d.buffer[Context].Buffer(), stackHeight, -1, true, bytes.Split(localsMsg, []byte{'\n'})); err != nil {
-		return errors.WithStack(err)
-	}
-
-	return nil
-}
-
-func (d *Delve) printThread(v *nvim.Nvim, cwd string, threads []*delveapi.Thread) error {
-	v.SetBufferOption(d.buffer[Context].Buffer(), "modifiable", true)
-	defer v.SetBufferOption(d.buffer[Context].Buffer(), "modifiable", false)
-
-	for _, thread := range threads {
-		printDebug("thread", thread.File)
-	}
-
-	return nil
+	return nvimutil.ToBufferLines(bytes.TrimSuffix(stacksMsg, []byte{'\n'})), hlLine, nil
 }
 
 // ----------------------------------------------------------------------------
@@ -17,34 +17,83 @@ func Register(p *plugin.Plugin, ctxt *context.Context) {
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvDebug", NArgs: "*", Eval: "[getcwd(), expand('%:p:h')]"}, d.cmdDebug)
 	// Connect connect to a headless debug server.
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvConnect", NArgs: "*", Eval: "[getcwd(), expand('%:p:h')]"}, d.cmdConnect)
+	// Exec execute a precompiled binary, and begin a debug session.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvExec", NArgs: "+", Eval: "[getcwd(), expand('%:p:h')]", Complete: "file"}, d.cmdExec)
+	// Test compile test binary and begin debugging program.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvTest", NArgs: "*", Eval: "[getcwd(), expand('%:p:h'), expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, d.cmdTest)
+	// Attach attach to running process and begin debugging.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvAttach", NArgs: "+", Eval: "[getcwd(), expand('%:p:h')]"}, d.cmdAttach)
+	// Core examine a core dump.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvCore", NArgs: "+", Eval: "[getcwd(), expand('%:p:h')]", Complete: "file"}, d.cmdCore)
+	// Trace compile and begin tracing program.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvTrace", NArgs: "+", Eval: "[getcwd(), expand('%:p:h')]"}, d.cmdTrace)
 
 	// Breakpoint sets a breakpoint.
-	p.HandleCommand(&plugin.CommandOptions{Name: "DlvBreakpoint", NArgs: "*", Eval: "[expand('%:p')]", Complete: "customlist,FunctionsCompletion"}, d.cmdBreakpoint)
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvBreakpoint", NArgs: "*", Eval: "[expand('%:p'), line('.')]", Complete: "customlist,FunctionsCompletion"}, d.cmdBreakpoint)
+	// Breakpoints lists, toggles or deletes the persistent breakpoints.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvBreakpoints", NArgs: "*", Eval: "get(b:, 'dlv_breakpoints_dir', expand('%:p:h'))"}, d.cmdBreakpoints)
 
 	// Stepping execution control
 	// Continue run until breakpoint or program termination.
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvContinue", NArgs: "*", Eval: "[expand('%:p:h')]"}, d.cmdContinue)
 	// Next step over to next source line.
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvNext", Eval: "[expand('%:p:h')]"}, d.cmdNext)
+	// Step single step through program.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvStep", Eval: "[expand('%:p:h')]"}, d.cmdStep)
+	// StepOut step out of the current function.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvStepOut", Eval: "[expand('%:p:h')]"}, d.cmdStepOut)
+	// StepInstruction single step a single cpu instruction.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvStepInstruction", Eval: "[expand('%:p:h')]"}, d.cmdStepInstruction)
+	// RunToCursor run until the cursor line.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvRunToCursor", Eval: "[expand('%:p:h'), expand('%:p'), line('.')]"}, d.cmdRunToCursor)
+
+	// Watch adds the watch expression which evaluated at each stops.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvWatch", NArgs: "+"}, d.cmdWatch)
+	// Unwatch removes the watch expression, or removes all if no args.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvUnwatch", NArgs: "*", Complete: "customlist,DlvWatchCompletion"}, d.cmdUnwatch)
+	// DlvWatchCompletion list of watch expressions for command completion.
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DlvWatchCompletion"}, d.WatchCompletion)
+
+	// Eval evaluates the expression, the visual selection or the word under the cursor.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvEval", NArgs: "*", Count: "0", Eval: "[expand('<cword>'), getpos(\"'<\"), getpos(\"'>\")]"}, d.cmdEval)
+
+	// halt stops the running program.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvHalt"}, d.cmdHalt)
 
 	// restart restart the process.
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvRestart"}, d.cmdRestart) // Restart process.
 
 	// stdin interactive mode
-	// TODO(zchee): Support contextual command completion
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvStdin"}, d.cmdStdin)
+	// DlvStdinCompletion contextual completion of the DlvStdin prompt.
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DlvStdinCompletion"}, d.StdinCompletion)
 	// RPC export
 	p.Handle("DlvStdin", d.stdin)
+	// DlvToggleVariable expands or collapses the variable of the context buffer.
+	p.Handle("DlvToggleVariable", d.toggleVariable)
+	// DlvToggleWatch expands or collapses the watch expression of the watch buffer.
+	p.Handle("DlvToggleWatch", d.toggleWatch)
+	// DlvToggleEval expands or collapses the variable of the eval preview buffer.
+	p.Handle("DlvToggleEval", d.toggleEval)
+	// DlvSelectFrame selects the goroutine or stack frame of the threads buffer.
+	p.Handle("DlvSelectFrame", d.selectFrame)
 	// FunctionsCompletion list of functions for command completion.
 	p.HandleFunction(&plugin.FunctionOptions{Name: "FunctionsCompletion"}, d.FunctionsCompletion)
 
 	// detach exit the debugger.
-	p.HandleCommand(&plugin.CommandOptions{Name: "DlvDetach"}, d.cmdDetach)
+	// The bang keeps the server running for the DlvReconnect.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvDetach", Bang: true}, d.cmdDetach)
+	// Reconnect reconnects to the last detached server of the project.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvReconnect", Eval: "[getcwd(), expand('%:p:h')]"}, d.cmdReconnect)
 
 	// State (WIP: for debug)
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvState"}, d.cmdState)
 
+	// autocmd BufReadPost
+	// places the signs of the persistent breakpoints.
+	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "BufReadPost", Group: "nvim-go", Pattern: "*.go", Eval: "expand('<afile>:p')"}, d.cmdBufReadPost)
+
 	// autocmd VimLeavePre
 	// FIXME(zchee): Why "[delve]*" pattern dose not handle autocmd?
-	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "VimLeavePre", Group: "nvim-go", Pattern: "*.go,terminal,context,thread"}, d.cmdDetach)
+	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "VimLeavePre", Group: "nvim-go", Pattern: "*.go,terminal,context,thread,disassembly,watch,output"}, d.cmdVimLeavePre)
 }
@@ -5,23 +5,40 @@
 package delve
 
 import (
+	"bytes"
+	"fmt"
 	"net"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
 
+	"nvim-go/config"
 	"nvim-go/nvimutil"
 
 	"github.com/neovim/go-client/nvim"
 	"github.com/pkg/errors"
 )
 
+// Config represents a configuration of the dlv server.
 type Config struct {
 	addr  string
 	flags []string
-	path  string
-	pid   int
+	// args arguments of the debug target program, passed after the "--".
+	args []string
+	path string
+	// dir working directory of the dlv server.
+	dir string
+	pid int
+	// pattern function regexp of the trace command.
+	pattern string
+	// core core file path of the core command.
+	core string
 }
 
-// startServer starts the delve headless server and replace server Stdout & Stderr.
+// startServer starts the delve headless server and streams the server Stdout & Stderr to the output buffer.
+// The server is not killed by the plugin exit, because the Stdout & Stderr are written to the file.
 func (d *Delve) startServer(cmd string, cfg Config) error {
 	dlv, err := exec.LookPath("dlv")
 	if err != nil {
@@ -30,47 +47,147 @@ func (d *Delve) startServer(cmd string, cfg Config) error {
 
 	switch cmd {
 	case "attach":
-		// TODO(zchee): implements
-	case "connect":
-		// connect command must be addr to the second argument
-		d.server = exec.Command(dlv, cmd, cfg.addr, "--log")
-	case "debug":
-		// debug command must be package path to the second argument, and need "--accept-multiclient" flag
-		d.server = exec.Command(dlv, cmd, cfg.path, "--headless", "--listen="+cfg.addr, "--accept-multiclient", "--api-version=2", "--log")
-	case "exec":
-		// TODO(zchee): implements
-	case "test":
-		// TODO(zchee): implements
+		// attach command must be pid to the second argument
+		d.server = exec.Command(dlv, cmd, strconv.Itoa(cfg.pid))
+	case "debug", "exec", "test":
+		// debug and test command must be package path, exec command must be binary path to the second argument
+		d.server = exec.Command(dlv, cmd, cfg.path)
+	case "core":
+		// core command must be binary path and core file path to the second and third arguments
+		d.server = exec.Command(dlv, cmd, cfg.path, cfg.core)
 	case "trace":
-		// TODO(zchee): implements
+		// dlv trace command does not support the headless mode, so starts the debug
+		// server and sets the tracepoints through the client
+		d.server = exec.Command(dlv, "debug", cfg.path)
+	default:
+		return errors.Errorf("unknown dlv command: %s", cmd)
+	}
+	// need "--accept-multiclient" flag for the delve client
+	d.server.Args = append(d.server.Args, "--headless", "--listen="+cfg.addr, "--accept-multiclient", "--api-version=2")
+	// the server logs are mixed into the program output, so enables only if debugging nvim-go
+	if config.DebugEnable {
+		d.server.Args = append(d.server.Args, "--log")
 	}
 	// append other flags such as build flags
 	d.server.Args = append(d.server.Args, cfg.flags...)
+	if len(cfg.args) > 0 {
+		d.server.Args = append(d.server.Args, "--")
+		d.server.Args = append(d.server.Args, cfg.args...)
+	}
+	d.server.Dir = cfg.dir
+	d.server.Env = d.bctxt.Env
+	// the debuggee program inherits the server stdout and stderr, which are
+	// written to the output log file and streamed to the output buffer
+	fname := outputFile(d.project)
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	logf, err := os.OpenFile(fname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// the server has the own file descriptor after started
+	defer logf.Close()
+	d.server.Stdout = logf
+	d.server.Stderr = logf
+
+	d.output.stop()
+	if err := d.startTail(d.project); err != nil {
+		return err
+	}
 
 	if err := d.server.Start(); err != nil {
-		err = errors.New(d.serverOut.String())
-		d.serverOut.Reset()
+		d.stopTail()
 		return errors.WithStack(err)
 	}
+	// notifies the server exit to the dialServer, such as the build failed
+	exited := make(chan error, 1)
+	go func(server *exec.Cmd) { exited <- server.Wait() }(d.server)
+	d.serverExited = exited
 
 	return nil
 }
 
-// dialServer dial the dlv launch the headless server.
-// `net.Dial` is better way?
-// http://stackoverflow.com/a/30838807/5228839
+// freeAddr returns the localhost address of the free tcp port.
+func freeAddr() (string, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer l.Close()
+
+	return l.Addr().String(), nil
+}
+
+const (
+	dialMinBackoff = 10 * time.Millisecond
+	dialMaxBackoff = 500 * time.Millisecond
+)
+
+// dialServer waits for the dlv headless server to listen on addr.
+// The dial is retried with the exponential backoff until the timeout of the
+// g:go#delve#timeout config, and is cancelled if the server exits before
+// listen such as the build failed.
 func (d *Delve) dialServer(v *nvim.Nvim, addr string) error {
-	defer nvimutil.EchohlAfter(v, "Delve", nvimutil.ProgressColor, "Ready")
 	nvimutil.EchoProgress(v, "Delve", "Wait for running dlv server")
 
+	timeout := time.After(time.Duration(config.DelveTimeout) * time.Millisecond)
+	backoff := dialMinBackoff
 	for {
-		conn, err := net.Dial("tcp", addr)
-		if err != nil {
-			continue
+		conn, err := net.DialTimeout("tcp", addr, backoff)
+		if err == nil {
+			conn.Close()
+			return nvimutil.EchohlAfter(v, "Delve", nvimutil.ProgressColor, "Ready")
+		}
+
+		select {
+		case err := <-d.serverExited:
+			if err == nil {
+				err = errors.New("dlv server exited")
+			}
+			// reads the rest of the output log before the report
+			if d.outputTail != nil {
+				d.outputTail.read()
+			}
+			return &serverExitError{err: err, output: d.output.setupOutput()}
+		case <-timeout:
+			return errors.Errorf("timed out waiting for the dlv server on %s", addr)
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > dialMaxBackoff {
+			backoff = dialMaxBackoff
 		}
-		defer conn.Close()
-		break
 	}
+}
 
-	return nil
+// serverExitError represents an error of the dlv server exited before listen.
+type serverExitError struct {
+	err    error
+	output []byte
+}
+
+func (e *serverExitError) Error() string {
+	out := bytes.TrimSpace(e.output)
+	if len(out) == 0 {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("%v: %s", e.err, out)
+}
+
+// reportServerError reports the dlv server startup error. The build errors
+// in the server output are shown on the error list.
+func (d *Delve) reportServerError(v *nvim.Nvim, cwd string, err error) error {
+	exitErr, ok := errors.Cause(err).(*serverExitError)
+	if !ok {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	errlist, perr := nvimutil.ParseError(exitErr.output, cwd, d.bctxt)
+	if perr != nil || len(errlist) == 0 {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	d.ctxt.SetErrlist("Delve", errlist)
+
+	return nvimutil.ErrorList(v, d.ctxt.CopyErrlist(), true)
 }
@@ -0,0 +1,220 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"nvim-go/context"
+	"nvim-go/internal/storage"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// session represents the last dlv server of the project, which is used for
+// reconnect to the detached server.
+type session struct {
+	Addr string `json:"addr"`
+	Mode string `json:"mode"`
+}
+
+// sessionFile returns the session file path of the project.
+func sessionFile(project string) string {
+	return storage.DataPath("delve", project, "session.json")
+}
+
+// loadSession loads the session of fname.
+// Returns nil if fname does not exist.
+func loadSession(fname string) (*session, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	s := new(session)
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.Wrapf(err, "invalid session file: %s", fname)
+	}
+
+	return s, nil
+}
+
+// saveSession saves s to fname.
+func saveSession(fname string, s *session) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(ioutil.WriteFile(fname, data, 0644))
+}
+
+// removeSession removes the session file of fname if exists.
+func removeSession(fname string) error {
+	if err := os.Remove(fname); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// detach!
+
+// disconnect disconnects the client from the server, and keeps the server and
+// the debuggee running for the DlvReconnect.
+func (d *Delve) disconnect(v *nvim.Nvim) error {
+	if !d.running() {
+		return nvimutil.ErrorWrap(v, errors.New("the debugging session is not running"))
+	}
+	if d.busy() {
+		return nvimutil.ErrorWrap(v, errors.New("the program is running. Use DlvHalt before detach"))
+	}
+
+	// forgets the server process, which is not killed by the next DlvDetach or VimLeavePre
+	d.server = nil
+	d.stopTail()
+	d.reset(v)
+
+	if d.pcSign != nil && d.pcSign.LastFile != "" {
+		d.pcSign.Unplace(v, d.pcSign.LastID, d.pcSign.LastFile)
+	}
+
+	return nvimutil.ErrorWrap(v, d.printTerminal("detach!", []byte(fmt.Sprintf("Detached from the dlv server at %s. Use DlvReconnect to reconnect", d.addr))))
+}
+
+// ----------------------------------------------------------------------------
+// reconnect
+
+func (d *Delve) cmdReconnect(v *nvim.Nvim, eval *delveEval) {
+	d.Pipeline = v.NewPipeline()
+	d.Batch = v.NewBatch()
+
+	go d.reconnect(v, eval)
+}
+
+// reconnectTimeout timeout of the dial to the detached server.
+const reconnectTimeout = 3 * time.Second
+
+// reconnect reconnects to the last detached dlv server of the project, and
+// rebuilds the debug buffers, breakpoints, signs and the stopped position.
+func (d *Delve) reconnect(v *nvim.Nvim, eval *delveEval) error {
+	if d.running() {
+		return nvimutil.ErrorWrap(v, errors.New("the debugging session is already running"))
+	}
+
+	project := breakpointsProject(eval.Dir)
+	fname := sessionFile(project)
+	s, err := loadSession(fname)
+	if err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	if s == nil {
+		return nvimutil.ErrorWrap(v, errors.Errorf("no detached dlv server of %s", project))
+	}
+
+	// the server may have exited, such as the debuggee exited or the machine restarted
+	conn, err := net.DialTimeout("tcp", s.Addr, reconnectTimeout)
+	if err != nil {
+		removeSession(fname)
+		return nvimutil.ErrorWrap(v, errors.Wrapf(err, "could not connect to the dlv server at %s", s.Addr))
+	}
+	conn.Close()
+
+	d.bctxt = context.NewBuildContext(eval.Cwd)
+	d.mode = s.Mode
+	d.project = project
+	d.addr = s.Addr
+	d.serverExited = nil
+	// the server was launched by the previous session, so DlvDetach does not
+	// kill the debuggee
+	d.launched = false
+
+	// reuses the debug buffers if still opened
+	if d.buffer == nil || !nvimutil.IsBufferValid(v, d.buffer[Terminal].Buffer()) {
+		if err := d.createDebugBuffer(); err != nil {
+			return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		}
+	}
+	if err := d.init(v, s.Addr); err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+	if err := d.printTerminal("reconnect", []byte(fmt.Sprintf("Reconnected to the dlv server at %s", s.Addr))); err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+	// replays the output log from the beginning
+	if err := d.startTail(project); err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	if !d.readOnly() {
+		if err := d.syncBreakpoints(v); err != nil {
+			return nvimutil.ErrorWrap(v, err)
+		}
+		if err := d.applyBreakpoints(v); err != nil {
+			return nvimutil.ErrorWrap(v, err)
+		}
+	}
+
+	state, err := d.client.GetState()
+	if err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+	if state.Exited || state.CurrentThread == nil {
+		return nil
+	}
+
+	return d.updateState(v, "", eval.Dir, state, nil)
+}
+
+// syncBreakpoints maps the breakpoints which are kept by the server to the
+// project breakpoints by the location, to avoid the duplicated breakpoints.
+func (d *Delve) syncBreakpoints(v *nvim.Nvim) error {
+	d.bpMu.Lock()
+	defer d.bpMu.Unlock()
+
+	served, err := d.client.ListBreakpoints()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	bps, err := d.projectBreakpoints(v, d.project)
+	if err != nil {
+		return err
+	}
+
+	d.bpIDs = make(map[int]int)
+	d.bpHitConds = make(map[int]string)
+	for _, bp := range bps {
+		if bp.Disabled {
+			continue
+		}
+		for _, sbp := range served {
+			// the negative ID is the internal breakpoint such as the unrecovered-panic
+			if sbp.ID < 0 || sbp.File != bp.File || sbp.Line != bp.Line {
+				continue
+			}
+			d.bpIDs[bp.ID] = sbp.ID
+			if bp.HitCond != "" {
+				d.bpHitConds[sbp.ID] = bp.HitCond
+			}
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,388 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nvim-go/internal/storage"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+// stdin
+
+func (d *Delve) cmdStdin(v *nvim.Nvim) {
+	go d.stdin(v)
+}
+
+// stdin sends the users input command to the internal delve terminal.
+// vim input() function args:
+//  input({prompt} [, {text} [, {completion}]])
+// More information of input() funciton and word completion are
+//  :help input()
+//  :help command-completion-custom
+func (d *Delve) stdin(v *nvim.Nvim) error {
+	history, err := loadStdinHistory(stdinHistoryFile(d.project))
+	if err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	// the input() history is shared with the other prompts, so adds the saved
+	// dlv command history to the input history once for the project
+	if d.stdinHistoryProject != d.project {
+		b := v.NewBatch()
+		for _, line := range history {
+			b.Call("histadd", nil, "input", line)
+		}
+		if err := b.Execute(); err != nil {
+			return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		}
+		d.stdinHistoryProject = d.project
+	}
+
+	var stdin interface{}
+	err = v.Call("input", &stdin, "(dlv) ", "", "customlist,DlvStdinCompletion")
+	if err != nil {
+		return nil
+	}
+	line := strings.TrimSpace(stdin.(string))
+	if line == "" {
+		return nil
+	}
+	// adds the trimmed line same as the saved history
+	if err := v.Call("histadd", nil, "input", line); err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+	history = appendStdinHistory(history, line)
+	if err := saveStdinHistory(stdinHistoryFile(d.project), history); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	name, args := splitTermCommand(line)
+	cmd, err := findTermCommand(name)
+	if err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	if !d.running() {
+		return nvimutil.ErrorWrap(v, errors.New("the debugging session is not running"))
+	}
+
+	// the output of each command is written to the own buffer, instead of the
+	// process-wide os.Stdout
+	var out bytes.Buffer
+	ctx := &termContext{v: v, w: &out, scope: d.evalScope(), dir: d.termDir()}
+	if cmd.exec {
+		// the execution control commands print the stopped location with the
+		// command by themselves, so prints the output only if any
+		d.runStdinCommand(ctx, cmd, args)
+		if out.Len() == 0 {
+			return nil
+		}
+		return d.printTerminal(line, out.Bytes())
+	}
+	// the delve server blocks the all requests until the program stops
+	if d.busy() {
+		return nvimutil.ErrorWrap(v, errors.New("the program is running. Use DlvHalt to stop the program"))
+	}
+	d.runStdinCommand(ctx, cmd, args)
+
+	return d.printTerminal(line, out.Bytes())
+}
+
+// runStdinCommand runs cmd with args, and writes the error of the command to
+// the context writer same as the delve terminal.
+func (d *Delve) runStdinCommand(ctx *termContext, cmd *termCommand, args string) {
+	if err := cmd.fn(d, ctx, args); err != nil {
+		fmt.Fprintf(ctx.w, "Command failed: %v\n", err)
+	}
+}
+
+// termDir returns the directory for the short file paths of the terminal
+// commands, which is the directory of the threads buffer.
+func (d *Delve) termDir() string {
+	d.threadMu.Lock()
+	dir := d.threadDir
+	d.threadMu.Unlock()
+	if dir == "" {
+		dir = d.project
+	}
+	return dir
+}
+
+// ----------------------------------------------------------------------------
+// history
+
+// stdinHistorySize maximum number of the DlvStdin command history.
+const stdinHistorySize = 100
+
+// stdinHistoryFile returns the DlvStdin command history file path of the project.
+func stdinHistoryFile(project string) string {
+	return storage.DataPath("delve", project, "stdin_history")
+}
+
+// loadStdinHistory loads the command history of fname, which is a command per
+// line from the oldest. Returns nil if fname does not exist.
+func loadStdinHistory(fname string) ([]string, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var history []string
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(line) > 0 {
+			history = append(history, string(line))
+		}
+	}
+	return history, nil
+}
+
+// saveStdinHistory saves history to fname.
+func saveStdinHistory(fname string, history []string) error {
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	data := []byte(strings.Join(history, "\n") + "\n")
+
+	return errors.WithStack(ioutil.WriteFile(fname, data, 0644))
+}
+
+// appendStdinHistory moves or appends line to the last of history, and drops
+// the oldest commands over the stdinHistorySize.
+func appendStdinHistory(history []string, line string) []string {
+	for i, h := range history {
+		if h == line {
+			history = append(history[:i], history[i+1:]...)
+			break
+		}
+	}
+	history = append(history, line)
+	if len(history) > stdinHistorySize {
+		history = history[len(history)-stdinHistorySize:]
+	}
+	return history
+}
+
+// ----------------------------------------------------------------------------
+// completion
+
+// termCommandNames returns the names and aliases of the terminal commands.
+func termCommandNames() []string {
+	var names []string
+	for _, cmd := range termCommands {
+		names = append(names, cmd.aliases...)
+	}
+	return names
+}
+
+// stdinCompleter returns the candidates of the command argument which has
+// the lead prefix.
+type stdinCompleter func(d *Delve, lead string) ([]string, error)
+
+// stdinCompleters completers of the command arguments.
+var stdinCompleters = map[string]stdinCompleter{
+	"b":           (*Delve).completeLocations,
+	"break":       (*Delve).completeLocations,
+	"t":           (*Delve).completeLocations,
+	"trace":       (*Delve).completeLocations,
+	"list":        (*Delve).completeLocations,
+	"ls":          (*Delve).completeLocations,
+	"funcs":       (*Delve).completeFunctions,
+	"disass":      (*Delve).completeFunctions,
+	"disassemble": (*Delve).completeFunctions,
+	"sources":     (*Delve).completeSources,
+	"p":           (*Delve).completeVariables,
+	"print":       (*Delve).completeVariables,
+	"set":         (*Delve).completeVariables,
+	"clear":       (*Delve).completeBreakpoints,
+	"cond":        (*Delve).completeBreakpoints,
+	"condition":   (*Delve).completeBreakpoints,
+	"on":          (*Delve).completeBreakpoints,
+	"source":      (*Delve).completeFiles,
+}
+
+// StdinCompletion returns the candidates of the DlvStdin prompt completion.
+// The first word is completed by the command names, and the rest of words
+// are completed by the command, such as the function names for the break.
+// The input() completion passes the whole input before the cursor as the
+// ArgLead, so the candidates are also the whole input.
+func (d *Delve) StdinCompletion(v *nvim.Nvim, a *nvim.CommandCompletionArgs) ([]string, error) {
+	line := a.ArgLead
+	fields := strings.Fields(line)
+	var lead string
+	if len(fields) > 0 && !strings.HasSuffix(line, " ") {
+		lead = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+	prefix := line[:len(line)-len(lead)]
+
+	var candidates []string
+	switch {
+	case len(fields) == 0:
+		candidates = termCommandNames()
+	case fields[0] == "h" || fields[0] == "help":
+		// the command names do not need the server
+		if len(fields) == 1 {
+			candidates = termCommandNames()
+		}
+	case fields[0] == "on" && len(fields) == 2:
+		candidates = onCommands
+	case d.running() && !d.busy():
+		completer, ok := stdinCompleters[fields[0]]
+		// the breakpoint commands take the ID to the first argument only
+		if !ok || (len(fields) > 1 && isBreakpointCommand(fields[0])) {
+			return []string{}, nil
+		}
+		var err error
+		candidates, err = completer(d, lead)
+		if err != nil {
+			return []string{}, errors.WithStack(err)
+		}
+	}
+
+	results := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if strings.HasPrefix(c, lead) {
+			results = append(results, prefix+c)
+		}
+	}
+	sort.Strings(results)
+
+	return results, nil
+}
+
+// isBreakpointCommand reports whether cmd takes the breakpoint ID argument.
+func isBreakpointCommand(cmd string) bool {
+	switch cmd {
+	case "clear", "cond", "condition", "on":
+		return true
+	}
+	return false
+}
+
+// onCommands commands which the on command can run on the breakpoint.
+var onCommands = []string{"print", "p", "stack", "bt", "goroutine", "args", "locals"}
+
+// completeFiles returns the file paths for the source command. The relative
+// lead is resolved from the terminal directory.
+func (d *Delve) completeFiles(lead string) ([]string, error) {
+	dir := d.termDir()
+	pattern := lead
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+	// filepath.Join drops the trailing separator of the directory
+	sep := string(filepath.Separator)
+	if (lead == "" || strings.HasSuffix(lead, sep)) && !strings.HasSuffix(pattern, sep) {
+		pattern += sep
+	}
+	matches, err := filepath.Glob(pattern + "*")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	files := make([]string, 0, len(matches))
+	for _, m := range matches {
+		// keeps the lead as typed, such as "./"
+		fname := lead + strings.TrimPrefix(m, pattern)
+		if pathutil.IsDir(m) {
+			fname += sep
+		}
+		files = append(files, fname)
+	}
+	return files, nil
+}
+
+// completeFunctions returns the function names of the debug target.
+// The empty lead is not completed, because the all functions of the program
+// are too many for the each Tab.
+func (d *Delve) completeFunctions(lead string) ([]string, error) {
+	if lead == "" {
+		return nil, nil
+	}
+	return d.client.ListFunctions("^" + regexp.QuoteMeta(lead))
+}
+
+// completeSources returns the source file paths of the debug target.
+// The empty lead is not completed as well as the completeFunctions.
+func (d *Delve) completeSources(lead string) ([]string, error) {
+	if lead == "" {
+		return nil, nil
+	}
+	return d.client.ListSources("^" + regexp.QuoteMeta(lead))
+}
+
+// completeLocations returns the function names and the source file paths,
+// which are the location spec of the break, trace and list commands.
+func (d *Delve) completeLocations(lead string) ([]string, error) {
+	funcs, err := d.completeFunctions(lead)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := d.completeSources(lead)
+	if err != nil {
+		return nil, err
+	}
+	return append(funcs, sources...), nil
+}
+
+// completeVariables returns the argument and local variable names of the
+// selected scope.
+func (d *Delve) completeVariables(lead string) ([]string, error) {
+	scope := d.evalScope()
+	args, err := d.client.ListFunctionArgs(scope, completionLoadConfig)
+	if err != nil {
+		return nil, err
+	}
+	locals, err := d.client.ListLocalVariables(scope, completionLoadConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(args)+len(locals))
+	for _, vars := range [][]delveapi.Variable{args, locals} {
+		for _, v := range vars {
+			names = append(names, v.Name)
+		}
+	}
+	return names, nil
+}
+
+// completeBreakpoints returns the breakpoint IDs of the delve server.
+func (d *Delve) completeBreakpoints(lead string) ([]string, error) {
+	bps, err := d.client.ListBreakpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(bps))
+	for _, bp := range bps {
+		// the negative ID is the internal breakpoint such as the unrecovered-panic
+		if bp.ID >= 0 {
+			ids = append(ids, strconv.Itoa(bp.ID))
+		}
+	}
+	return ids, nil
+}
+
+// completionLoadConfig load config of the variable names completion, which
+// does not need the values.
+var completionLoadConfig = delveapi.LoadConfig{}
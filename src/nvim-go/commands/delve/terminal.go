@@ -0,0 +1,875 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"bufio"
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"nvim-go/pathutil"
+
+	delveterm "github.com/derekparker/delve/pkg/terminal"
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// The delve terminal package prints the command output to the os.Stdout
+// only, so the DlvStdin runs the terminal commands by itself through the rpc2
+// client, and writes the output to the writer of each call.
+
+// termContext represents a context of the terminal command call.
+type termContext struct {
+	v *nvim.Nvim
+	// w writer of the command output.
+	w io.Writer
+	// scope goroutine and frame scope of the command.
+	scope delveapi.EvalScope
+	// dir directory for the short file paths.
+	dir string
+}
+
+// termCommand represents a delve terminal command.
+type termCommand struct {
+	aliases []string
+	fn      func(d *Delve, ctx *termContext, args string) error
+	// exec whether the command prints the result to the terminal buffer by
+	// itself, such as the execution control commands.
+	exec bool
+	help string
+}
+
+// termCommands delve terminal commands supported by the DlvStdin.
+// Initialized by init for avoid the initialization loop of the help command.
+var termCommands []termCommand
+
+func init() {
+	termCommands = []termCommand{
+		{aliases: []string{"help", "h"}, fn: (*Delve).termHelp, help: "Prints the help message."},
+		{aliases: []string{"break", "b"}, fn: (*Delve).termBreak, help: "Sets a breakpoint.\n\n\tbreak [name] <linespec>"},
+		{aliases: []string{"trace", "t"}, fn: (*Delve).termTrace, help: "Set tracepoint.\n\n\ttrace [name] <linespec>"},
+		{aliases: []string{"restart", "r"}, fn: (*Delve).termRestart, exec: true, help: "Restart process."},
+		{aliases: []string{"continue", "c"}, fn: (*Delve).termContinue, exec: true, help: "Run until breakpoint or program termination."},
+		{aliases: []string{"step", "s"}, fn: (*Delve).termStep, exec: true, help: "Single step through program."},
+		{aliases: []string{"step-instruction", "si"}, fn: (*Delve).termStepInstruction, exec: true, help: "Single step a single cpu instruction."},
+		{aliases: []string{"next", "n"}, fn: (*Delve).termNext, exec: true, help: "Step over to next source line."},
+		{aliases: []string{"stepout"}, fn: (*Delve).termStepOut, exec: true, help: "Step out of the current function."},
+		{aliases: []string{"threads"}, fn: (*Delve).termThreads, help: "Print out info for every traced thread."},
+		{aliases: []string{"thread", "tr"}, fn: (*Delve).termThread, help: "Switch to the specified thread.\n\n\tthread <id>"},
+		{aliases: []string{"clear"}, fn: (*Delve).termClear, help: "Deletes breakpoint.\n\n\tclear <breakpoint name or id>"},
+		{aliases: []string{"clearall"}, fn: (*Delve).termClearAll, help: "Deletes multiple breakpoints.\n\n\tclearall [<linespec>]"},
+		{aliases: []string{"goroutines"}, fn: (*Delve).termGoroutines, help: "List program goroutines."},
+		{aliases: []string{"goroutine"}, fn: (*Delve).termGoroutine, help: "Shows or changes current goroutine.\n\n\tgoroutine\n\tgoroutine <id>\n\tgoroutine <id> <command>"},
+		{aliases: []string{"breakpoints", "bp"}, fn: (*Delve).termBreakpoints, help: "Print out info for active breakpoints."},
+		{aliases: []string{"print", "p"}, fn: (*Delve).termPrint, help: "Evaluate an expression.\n\n\tprint <expression>"},
+		{aliases: []string{"set"}, fn: (*Delve).termSet, help: "Changes the value of a variable.\n\n\tset <variable> = <value>"},
+		{aliases: []string{"sources"}, fn: (*Delve).termSources, help: "Print list of source files.\n\n\tsources [<regex>]"},
+		{aliases: []string{"funcs"}, fn: (*Delve).termFuncs, help: "Print list of functions.\n\n\tfuncs [<regex>]"},
+		{aliases: []string{"types"}, fn: (*Delve).termTypes, help: "Print list of types.\n\n\ttypes [<regex>]"},
+		{aliases: []string{"args"}, fn: (*Delve).termArgs, help: "Print function arguments.\n\n\targs [-v] [<regex>]"},
+		{aliases: []string{"locals"}, fn: (*Delve).termLocals, help: "Print local variables.\n\n\tlocals [-v] [<regex>]"},
+		{aliases: []string{"vars"}, fn: (*Delve).termVars, help: "Print package variables.\n\n\tvars [-v] [<regex>]"},
+		{aliases: []string{"regs"}, fn: (*Delve).termRegs, help: "Print contents of CPU registers.\n\n\tregs [-a]"},
+		{aliases: []string{"exit", "quit", "q"}, fn: (*Delve).termExit, exec: true, help: "Exit the debugger."},
+		{aliases: []string{"list", "ls"}, fn: (*Delve).termList, help: "Show source code.\n\n\tlist [<linespec>]"},
+		{aliases: []string{"stack", "bt"}, fn: (*Delve).termStack, help: "Print stack trace.\n\n\tstack [<depth>] [-full]"},
+		{aliases: []string{"frame"}, fn: (*Delve).termFrame, help: "Changes the current frame, or executes command on a different frame.\n\n\tframe <m>\n\tframe <m> <command>"},
+		{aliases: []string{"source"}, fn: (*Delve).termSource, help: "Executes a file containing a list of delve commands.\n\n\tsource <path>"},
+		{aliases: []string{"disassemble", "disass"}, fn: (*Delve).termDisassemble, help: "Disassembler.\n\n\tdisassemble [-a <start> <end>] [-l <locspec>]"},
+		{aliases: []string{"on"}, fn: (*Delve).termOn, help: "Executes a command when a breakpoint is hit.\n\n\ton <breakpoint name or id> <command>\n\nSupported commands: print, stack, goroutine, args and locals."},
+		{aliases: []string{"condition", "cond"}, fn: (*Delve).termCondition, help: "Set breakpoint condition.\n\n\tcondition <breakpoint name or id> <boolean expression>"},
+	}
+}
+
+// findTermCommand returns the terminal command of name.
+func findTermCommand(name string) (*termCommand, error) {
+	for i := range termCommands {
+		for _, alias := range termCommands[i].aliases {
+			if alias == name {
+				return &termCommands[i], nil
+			}
+		}
+	}
+	return nil, errors.Errorf("command not available: %s", name)
+}
+
+// splitTermCommand splits line to the command name and the args.
+func splitTermCommand(line string) (string, string) {
+	cmd := strings.SplitN(strings.TrimSpace(line), " ", 2)
+	if len(cmd) == 1 {
+		return cmd[0], ""
+	}
+	return cmd[0], strings.TrimSpace(cmd[1])
+}
+
+// runTermCommand runs the line of the terminal command.
+func (d *Delve) runTermCommand(ctx *termContext, line string) error {
+	name, args := splitTermCommand(line)
+	cmd, err := findTermCommand(name)
+	if err != nil {
+		return err
+	}
+	return cmd.fn(d, ctx, args)
+}
+
+// ----------------------------------------------------------------------------
+// help
+
+func (d *Delve) termHelp(ctx *termContext, args string) error {
+	if args != "" {
+		cmd, err := findTermCommand(args)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(ctx.w, cmd.help)
+		return nil
+	}
+
+	fmt.Fprintln(ctx.w, "The following commands are available:")
+	tw := tabwriter.NewWriter(ctx.w, 0, 8, 0, '-', 0)
+	for _, cmd := range termCommands {
+		h := cmd.help
+		if idx := strings.Index(h, "\n"); idx >= 0 {
+			h = h[:idx]
+		}
+		if len(cmd.aliases) > 1 {
+			fmt.Fprintf(tw, "    %s (alias: %s) \t %s\n", cmd.aliases[0], strings.Join(cmd.aliases[1:], " | "), h)
+		} else {
+			fmt.Fprintf(tw, "    %s \t %s\n", cmd.aliases[0], h)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Fprintln(ctx.w, "Type help followed by a command for full documentation.")
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// execution control
+
+// The execution control commands print the stopped location to the terminal
+// buffer, and update the debug buffers by themselves.
+
+func (d *Delve) termContinue(ctx *termContext, args string) error {
+	return d.cont(ctx.v, nil, &continueEval{Dir: ctx.dir})
+}
+
+func (d *Delve) termNext(ctx *termContext, args string) error {
+	return d.next(ctx.v, &stepEval{Dir: ctx.dir})
+}
+
+func (d *Delve) termStep(ctx *termContext, args string) error {
+	return d.step(ctx.v, &stepEval{Dir: ctx.dir})
+}
+
+func (d *Delve) termStepOut(ctx *termContext, args string) error {
+	return d.stepOut(ctx.v, &stepEval{Dir: ctx.dir})
+}
+
+func (d *Delve) termStepInstruction(ctx *termContext, args string) error {
+	return d.stepInstruction(ctx.v, &stepEval{Dir: ctx.dir})
+}
+
+func (d *Delve) termRestart(ctx *termContext, args string) error {
+	return d.restart(ctx.v)
+}
+
+func (d *Delve) termExit(ctx *termContext, args string) error {
+	return d.detach(ctx.v, false)
+}
+
+func (d *Delve) termThread(ctx *termContext, args string) error {
+	id, err := strconv.Atoi(args)
+	if err != nil {
+		return errors.Errorf("invalid thread id: %s", args)
+	}
+	state, err := d.client.SwitchThread(id)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	cThread, err := d.showState(ctx.v, ctx.dir, state, nil)
+	if err != nil || cThread == nil {
+		return err
+	}
+	fmt.Fprintf(ctx.w, "%s\n", stoppedMessage(cThread, ctx.dir))
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// breakpoints
+
+func (d *Delve) termBreak(ctx *termContext, args string) error {
+	return d.termSetBreakpoint(ctx, args, false)
+}
+
+func (d *Delve) termTrace(ctx *termContext, args string) error {
+	return d.termSetBreakpoint(ctx, args, true)
+}
+
+// termSetBreakpoint sets the breakpoint or tracepoint of the args, which is
+// the optional name and the linespec.
+// Note that the breakpoint is not saved to the persistent breakpoints.
+func (d *Delve) termSetBreakpoint(ctx *termContext, args string, tracepoint bool) error {
+	if args == "" {
+		return errors.New("address required")
+	}
+
+	req := &delveapi.Breakpoint{Tracepoint: tracepoint}
+	locspec := args
+	if argv := strings.SplitN(args, " ", 2); len(argv) == 2 && delveapi.ValidBreakpointName(argv[0]) == nil {
+		req.Name = argv[0]
+		locspec = argv[1]
+	}
+	locs, err := d.client.FindLocation(ctx.scope, locspec)
+	if err != nil && req.Name != "" {
+		// the first word may be the part of the linespec
+		req.Name = ""
+		locs, err = d.client.FindLocation(ctx.scope, args)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, loc := range locs {
+		req.Addr = loc.PC
+		bp, err := d.client.CreateBreakpoint(req)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Fprintf(ctx.w, "%s set at %s\n", breakpointName(bp), breakpointLocation(bp, ctx.dir))
+	}
+	return nil
+}
+
+func (d *Delve) termBreakpoints(ctx *termContext, args string) error {
+	bps, err := d.client.ListBreakpoints()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	sort.Sort(delveterm.ByID(bps))
+
+	for _, bp := range bps {
+		fmt.Fprintf(ctx.w, "%s at %s (%d)\n", breakpointName(bp), breakpointLocation(bp, ctx.dir), bp.TotalHitCount)
+		if bp.Cond != "" {
+			fmt.Fprintf(ctx.w, "\tcond %s\n", bp.Cond)
+		}
+		// the commands set by the on command
+		if bp.Stacktrace > 0 {
+			fmt.Fprintf(ctx.w, "\tstack %d\n", bp.Stacktrace)
+		}
+		if bp.Goroutine {
+			fmt.Fprintln(ctx.w, "\tgoroutine")
+		}
+		if bp.LoadArgs != nil {
+			fmt.Fprintf(ctx.w, "\targs%s\n", varArgsFlag(*bp.LoadArgs))
+		}
+		if bp.LoadLocals != nil {
+			fmt.Fprintf(ctx.w, "\tlocals%s\n", varArgsFlag(*bp.LoadLocals))
+		}
+		for _, v := range bp.Variables {
+			fmt.Fprintf(ctx.w, "\tprint %s\n", v)
+		}
+	}
+	return nil
+}
+
+// findBreakpoint returns the breakpoint of the ID or name.
+func (d *Delve) findBreakpoint(arg string) (*delveapi.Breakpoint, error) {
+	if id, err := strconv.Atoi(arg); err == nil {
+		return d.client.GetBreakpoint(id)
+	}
+	return d.client.GetBreakpointByName(arg)
+}
+
+func (d *Delve) termClear(ctx *termContext, args string) error {
+	if args == "" {
+		return errors.New("not enough arguments")
+	}
+	bp, err := d.findBreakpoint(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := d.client.ClearBreakpoint(bp.ID); err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Fprintf(ctx.w, "%s cleared at %s\n", breakpointName(bp), breakpointLocation(bp, ctx.dir))
+	return nil
+}
+
+func (d *Delve) termClearAll(ctx *termContext, args string) error {
+	bps, err := d.client.ListBreakpoints()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var locs []delveapi.Location
+	if args != "" {
+		locs, err = d.client.FindLocation(ctx.scope, args)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for _, bp := range bps {
+		// the negative ID is the internal breakpoint such as the unrecovered-panic
+		if bp.ID < 0 || !matchLocations(locs, bp.Addr) {
+			continue
+		}
+		if _, err := d.client.ClearBreakpoint(bp.ID); err != nil {
+			fmt.Fprintf(ctx.w, "Couldn't delete %s at %s: %v\n", breakpointName(bp), breakpointLocation(bp, ctx.dir), err)
+			continue
+		}
+		fmt.Fprintf(ctx.w, "%s cleared at %s\n", breakpointName(bp), breakpointLocation(bp, ctx.dir))
+	}
+	return nil
+}
+
+// matchLocations reports whether the locs contains the addr, or locs is empty.
+func matchLocations(locs []delveapi.Location, addr uint64) bool {
+	if len(locs) == 0 {
+		return true
+	}
+	for _, loc := range locs {
+		if loc.PC == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Delve) termCondition(ctx *termContext, args string) error {
+	argv := strings.SplitN(args, " ", 2)
+	if len(argv) < 2 {
+		return errors.New("not enough arguments")
+	}
+	bp, err := d.findBreakpoint(argv[0])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	bp.Cond = argv[1]
+
+	return errors.WithStack(d.client.AmendBreakpoint(bp))
+}
+
+// termOn amends the breakpoint to run the command when the breakpoint is hit.
+// The results are printed with the stopped location.
+func (d *Delve) termOn(ctx *termContext, args string) error {
+	argv := strings.SplitN(args, " ", 3)
+	if len(argv) < 2 {
+		return errors.New("not enough arguments")
+	}
+	var cmdArgs string
+	if len(argv) == 3 {
+		cmdArgs = strings.TrimSpace(argv[2])
+	}
+	bp, err := d.findBreakpoint(argv[0])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	cmd, err := findTermCommand(argv[1])
+	if err != nil {
+		return err
+	}
+
+	switch cmd.aliases[0] {
+	case "print":
+		if cmdArgs == "" {
+			return errors.New("not enough arguments")
+		}
+		bp.Variables = append(bp.Variables, cmdArgs)
+	case "stack":
+		depth, _, err := parseStackArgs(cmdArgs)
+		if err != nil {
+			return err
+		}
+		bp.Stacktrace = depth
+	case "goroutine":
+		if cmdArgs != "" {
+			return errors.New("too many arguments to goroutine")
+		}
+		bp.Goroutine = true
+	case "args", "locals":
+		filter, cfg := parseVarArgs(cmdArgs)
+		if filter != "" {
+			return errors.New("filter not supported on breakpoint")
+		}
+		if cmd.aliases[0] == "args" {
+			bp.LoadArgs = &cfg
+		} else {
+			bp.LoadLocals = &cfg
+		}
+	default:
+		return errors.Errorf("%s is not supported on breakpoint", argv[1])
+	}
+
+	return errors.WithStack(d.client.AmendBreakpoint(bp))
+}
+
+// breakpointName returns the kind and the name or ID of bp.
+func breakpointName(bp *delveapi.Breakpoint) string {
+	kind := "Breakpoint"
+	if bp.Tracepoint {
+		kind = "Tracepoint"
+	}
+	id := bp.Name
+	if id == "" {
+		id = strconv.Itoa(bp.ID)
+	}
+	return kind + " " + id
+}
+
+// breakpointLocation returns the address, function name and short file path of bp.
+func breakpointLocation(bp *delveapi.Breakpoint, dir string) string {
+	fname := pathutil.ShortFilePath(bp.File, dir)
+	if bp.FunctionName != "" {
+		return fmt.Sprintf("%#v for %s() %s:%d", bp.Addr, bp.FunctionName, fname, bp.Line)
+	}
+	return fmt.Sprintf("%#v for %s:%d", bp.Addr, fname, bp.Line)
+}
+
+// ----------------------------------------------------------------------------
+// variables
+
+func (d *Delve) termPrint(ctx *termContext, args string) error {
+	if args == "" {
+		return errors.New("not enough arguments")
+	}
+	val, err := d.client.EvalVariable(ctx.scope, args, delveterm.LongLoadConfig)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Fprintln(ctx.w, val.MultilineString(""))
+	return nil
+}
+
+func (d *Delve) termSet(ctx *termContext, args string) error {
+	// the '=' is not the operator of the Go expression, so splits the args by
+	// the position of the syntax error
+	_, err := parser.ParseExpr(args)
+	if err == nil {
+		return errors.New("syntax error '=' not found")
+	}
+	el, ok := err.(scanner.ErrorList)
+	if !ok || el[0].Msg != "expected '==', found '='" {
+		return errors.WithStack(err)
+	}
+
+	lexpr := args[:el[0].Pos.Offset]
+	rexpr := args[el[0].Pos.Offset+1:]
+	return errors.WithStack(d.client.SetVariable(ctx.scope, lexpr, rexpr))
+}
+
+// parseVarArgs parses the args of the args, locals and vars commands, which
+// are the optional "-v" flag and the filter regexp.
+func parseVarArgs(args string) (string, delveapi.LoadConfig) {
+	if args == "-v" {
+		return "", delveterm.LongLoadConfig
+	}
+	if strings.HasPrefix(args, "-v ") {
+		return strings.TrimSpace(args[3:]), delveterm.LongLoadConfig
+	}
+	return args, delveterm.ShortLoadConfig
+}
+
+// varArgsFlag returns the " -v" flag of the args and locals commands if cfg
+// is the long load config.
+func varArgsFlag(cfg delveapi.LoadConfig) string {
+	if cfg == delveterm.LongLoadConfig {
+		return " -v"
+	}
+	return ""
+}
+
+// printVariables prints the vars which matches the filter regexp.
+func printVariables(w io.Writer, kind string, vars []delveapi.Variable, filter string, cfg delveapi.LoadConfig) error {
+	re, err := regexp.Compile(filter)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var matched bool
+	for _, v := range vars {
+		if !re.MatchString(v.Name) {
+			continue
+		}
+		matched = true
+		if cfg == delveterm.ShortLoadConfig {
+			fmt.Fprintf(w, "%s = %s\n", v.Name, v.SinglelineString())
+		} else {
+			fmt.Fprintf(w, "%s = %s\n", v.Name, v.MultilineString(""))
+		}
+	}
+	if !matched {
+		fmt.Fprintf(w, "(no %s)\n", kind)
+	}
+	return nil
+}
+
+func (d *Delve) termArgs(ctx *termContext, args string) error {
+	filter, cfg := parseVarArgs(args)
+	vars, err := d.client.ListFunctionArgs(ctx.scope, cfg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return printVariables(ctx.w, "args", vars, filter, cfg)
+}
+
+func (d *Delve) termLocals(ctx *termContext, args string) error {
+	filter, cfg := parseVarArgs(args)
+	vars, err := d.client.ListLocalVariables(ctx.scope, cfg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return printVariables(ctx.w, "locals", vars, filter, cfg)
+}
+
+func (d *Delve) termVars(ctx *termContext, args string) error {
+	filter, cfg := parseVarArgs(args)
+	vars, err := d.client.ListPackageVariables(filter, cfg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return printVariables(ctx.w, "vars", vars, filter, cfg)
+}
+
+func (d *Delve) termRegs(ctx *termContext, args string) error {
+	regs, err := d.client.ListRegisters(0, args == "-a")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Fprintln(ctx.w, regs)
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// symbols
+
+// printSorted prints the sorted list.
+func printSorted(w io.Writer, list []string, err error) error {
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	sort.Strings(list)
+	for _, s := range list {
+		fmt.Fprintln(w, s)
+	}
+	return nil
+}
+
+func (d *Delve) termSources(ctx *termContext, args string) error {
+	list, err := d.client.ListSources(args)
+	return printSorted(ctx.w, list, err)
+}
+
+func (d *Delve) termFuncs(ctx *termContext, args string) error {
+	list, err := d.client.ListFunctions(args)
+	return printSorted(ctx.w, list, err)
+}
+
+func (d *Delve) termTypes(ctx *termContext, args string) error {
+	list, err := d.client.ListTypes(args)
+	return printSorted(ctx.w, list, err)
+}
+
+// ----------------------------------------------------------------------------
+// goroutines and threads
+
+func (d *Delve) termGoroutines(ctx *termContext, args string) error {
+	goroutines, err := d.client.ListGoroutines()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	sort.Sort(byGroutineID(goroutines))
+
+	fmt.Fprintf(ctx.w, "[%d goroutines]\n", len(goroutines))
+	for _, g := range goroutines {
+		mark := " "
+		if g.ID == d.selectedGoroutine(ctx.scope) {
+			mark = "*"
+		}
+		fmt.Fprintf(ctx.w, "%s Goroutine %d - %s\n", mark, g.ID, locationString(g.UserCurrentLoc, ctx.dir))
+	}
+	return nil
+}
+
+// termGoroutine prints the selected goroutine, or selects the goroutine of
+// the args. If the command follows the goroutine ID, runs the command on the
+// goroutine without changing the selection.
+func (d *Delve) termGoroutine(ctx *termContext, args string) error {
+	if args == "" {
+		fmt.Fprintf(ctx.w, "Goroutine %d\n", d.selectedGoroutine(ctx.scope))
+		return nil
+	}
+
+	argv := strings.SplitN(args, " ", 2)
+	id, err := strconv.Atoi(argv[0])
+	if err != nil {
+		return errors.Errorf("invalid goroutine id: %s", argv[0])
+	}
+	scoped := *ctx
+	scoped.scope = delveapi.EvalScope{GoroutineID: id}
+	if len(argv) == 2 {
+		return d.runTermCommand(&scoped, argv[1])
+	}
+
+	d.setScope(id, 0)
+	fmt.Fprintf(ctx.w, "Switched to goroutine %d\n", id)
+	return d.refreshScope(ctx.dir)
+}
+
+// termFrame selects the frame of the args. If the command follows the frame
+// index, runs the command on the frame without changing the selection.
+func (d *Delve) termFrame(ctx *termContext, args string) error {
+	argv := strings.SplitN(args, " ", 2)
+	frame, err := strconv.Atoi(argv[0])
+	if err != nil {
+		return errors.Errorf("invalid frame: %s", argv[0])
+	}
+	scoped := *ctx
+	scoped.scope.Frame = frame
+	if len(argv) == 2 {
+		return d.runTermCommand(&scoped, argv[1])
+	}
+
+	d.setScope(ctx.scope.GoroutineID, frame)
+	fmt.Fprintf(ctx.w, "Switched to frame %d\n", frame)
+	return d.refreshScope(ctx.dir)
+}
+
+// selectedGoroutine returns the goroutine ID of the scope.
+func (d *Delve) selectedGoroutine(scope delveapi.EvalScope) int {
+	if scope.GoroutineID != -1 {
+		return scope.GoroutineID
+	}
+	d.scopeMu.Lock()
+	defer d.scopeMu.Unlock()
+
+	return d.goroutineID
+}
+
+// refreshScope re-renders the variables, watch expressions and threads of the
+// selected scope.
+func (d *Delve) refreshScope(dir string) error {
+	if err := d.refreshVariables(); err != nil {
+		return err
+	}
+	if err := d.printWatch(); err != nil {
+		return err
+	}
+	return d.printThreads(dir)
+}
+
+func (d *Delve) termThreads(ctx *termContext, args string) error {
+	threads, err := d.client.ListThreads()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	state, err := d.client.GetState()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	sort.Sort(byThreadID(threads))
+
+	for _, th := range threads {
+		mark := " "
+		if state.CurrentThread != nil && th.ID == state.CurrentThread.ID {
+			mark = "*"
+		}
+		loc := delveapi.Location{PC: th.PC, File: th.File, Line: th.Line, Function: th.Function}
+		fmt.Fprintf(ctx.w, "%s Thread %d at %#v %s\n", mark, th.ID, th.PC, locationString(loc, ctx.dir))
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// stack
+
+// parseStackArgs parses the args of the stack command, which are the optional
+// depth and the "-full" flag.
+func parseStackArgs(args string) (int, bool, error) {
+	depth := 10
+	var full bool
+	for _, arg := range strings.Fields(args) {
+		if arg == "-full" {
+			full = true
+			continue
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return 0, false, errors.Errorf("depth must be a number: %s", arg)
+		}
+		depth = n
+	}
+	return depth, full, nil
+}
+
+func (d *Delve) termStack(ctx *termContext, args string) error {
+	depth, full, err := parseStackArgs(args)
+	if err != nil {
+		return err
+	}
+	var cfg *delveapi.LoadConfig
+	if full {
+		cfg = &delveterm.ShortLoadConfig
+	}
+
+	frames, err := d.client.Stacktrace(ctx.scope.GoroutineID, depth, cfg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i, f := range frames {
+		fn := "(nil)"
+		if f.Function != nil {
+			fn = f.Function.Name
+		}
+		fmt.Fprintf(ctx.w, "%2d  %#016x in %s\n", i, f.PC, fn)
+		fmt.Fprintf(ctx.w, "    at %s:%d\n", pathutil.ShortFilePath(f.File, ctx.dir), f.Line)
+		for _, vars := range [][]delveapi.Variable{f.Arguments, f.Locals} {
+			for _, v := range vars {
+				fmt.Fprintf(ctx.w, "        %s = %s\n", v.Name, v.SinglelineString())
+			}
+		}
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// source
+
+// termListContext number of lines before and after the listed line.
+const termListContext = 5
+
+func (d *Delve) termList(ctx *termContext, args string) error {
+	var file string
+	var line int
+	var arrow bool
+	if args == "" {
+		frames, err := d.client.Stacktrace(ctx.scope.GoroutineID, ctx.scope.Frame, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if ctx.scope.Frame >= len(frames) {
+			return errors.Errorf("frame %d does not exist in goroutine %d", ctx.scope.Frame, ctx.scope.GoroutineID)
+		}
+		file, line, arrow = frames[ctx.scope.Frame].File, frames[ctx.scope.Frame].Line, true
+	} else {
+		locs, err := d.client.FindLocation(ctx.scope, args)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(locs) != 1 {
+			return errors.Errorf("ambiguous location: %s", args)
+		}
+		file, line = locs[0].File, locs[0].Line
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for i := 1; sc.Scan() && i <= line+termListContext; i++ {
+		if i < line-termListContext {
+			continue
+		}
+		prefix := "  "
+		if arrow && i == line {
+			prefix = "=>"
+		}
+		fmt.Fprintf(ctx.w, "%s%4d:\t%s\n", prefix, i, sc.Text())
+	}
+	return errors.WithStack(sc.Err())
+}
+
+const disassembleUsage = "wrong number of arguments: disassemble [-a <start> <end>] [-l <locspec>]"
+
+func (d *Delve) termDisassemble(ctx *termContext, args string) error {
+	argv := strings.Fields(args)
+
+	var insts delveapi.AsmInstructions
+	var err error
+	switch {
+	case len(argv) == 0:
+		locs, ferr := d.client.FindLocation(ctx.scope, "+0")
+		if ferr != nil {
+			return errors.WithStack(ferr)
+		}
+		insts, err = d.client.DisassemblePC(ctx.scope, locs[0].PC, delveapi.IntelFlavour)
+	case argv[0] == "-a" && len(argv) == 3:
+		start, perr := strconv.ParseUint(argv[1], 0, 64)
+		if perr != nil {
+			return errors.Errorf("wrong argument: %s is not a number", argv[1])
+		}
+		end, perr := strconv.ParseUint(argv[2], 0, 64)
+		if perr != nil {
+			return errors.Errorf("wrong argument: %s is not a number", argv[2])
+		}
+		insts, err = d.client.DisassembleRange(ctx.scope, start, end, delveapi.IntelFlavour)
+	case argv[0] == "-l" && len(argv) >= 2:
+		locs, ferr := d.client.FindLocation(ctx.scope, strings.Join(argv[1:], " "))
+		if ferr != nil {
+			return errors.WithStack(ferr)
+		}
+		if len(locs) != 1 {
+			return errors.New("expression specifies multiple locations")
+		}
+		insts, err = d.client.DisassemblePC(ctx.scope, locs[0].PC, delveapi.IntelFlavour)
+	default:
+		return errors.New(disassembleUsage)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	delveterm.DisasmPrint(insts, ctx.w)
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// command file
+
+// termSource runs the commands of the file line by line. The empty and "#"
+// comment lines are skipped, and the failed commands are reported with the
+// line number. The relative path is resolved from the context directory.
+func (d *Delve) termSource(ctx *termContext, args string) error {
+	if args == "" {
+		return errors.New("wrong number of arguments: source <filename>")
+	}
+	fname := args
+	if !filepath.IsAbs(fname) {
+		fname = filepath.Join(ctx.dir, fname)
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	scan := bufio.NewScanner(f)
+	for lineno := 1; scan.Scan(); lineno++ {
+		line := strings.TrimSpace(scan.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if err := d.runTermCommand(ctx, line); err != nil {
+			fmt.Fprintf(ctx.w, "%s:%d: %v\n", args, lineno, err)
+		}
+	}
+	return errors.WithStack(scan.Err())
+}
@@ -0,0 +1,290 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	delveterm "github.com/derekparker/delve/pkg/terminal"
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/pkg/errors"
+)
+
+func TestSplitTermCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantName string
+		wantArgs string
+	}{
+		{name: "empty", line: "", wantName: "", wantArgs: ""},
+		{name: "no args", line: "continue", wantName: "continue", wantArgs: ""},
+		{name: "args", line: "print s.Name", wantName: "print", wantArgs: "s.Name"},
+		{name: "args with spaces", line: "set i = 1", wantName: "set", wantArgs: "i = 1"},
+		{name: "surrounding spaces", line: "  stack   5 -full  ", wantName: "stack", wantArgs: "5 -full"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			name, args := splitTermCommand(tt.line)
+			if name != tt.wantName || args != tt.wantArgs {
+				t.Errorf("splitTermCommand(%q) = (%q, %q), want (%q, %q)", tt.line, name, args, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFindTermCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      string
+		wantName string
+		wantExec bool
+		wantErr  bool
+	}{
+		{name: "command name", cmd: "continue", wantName: "continue", wantExec: true},
+		{name: "alias", cmd: "bt", wantName: "stack"},
+		{name: "multiple aliases", cmd: "q", wantName: "exit", wantExec: true},
+		{name: "not exec command", cmd: "source", wantName: "source"},
+		{name: "unknown command", cmd: "foo", wantErr: true},
+		{name: "empty", cmd: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findTermCommand(tt.cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findTermCommand(%q) error = %v, wantErr %v", tt.cmd, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.aliases[0] != tt.wantName {
+				t.Errorf("findTermCommand(%q) = %v, want %v", tt.cmd, got.aliases[0], tt.wantName)
+			}
+			if got.exec != tt.wantExec {
+				t.Errorf("findTermCommand(%q).exec = %v, want %v", tt.cmd, got.exec, tt.wantExec)
+			}
+		})
+	}
+}
+
+func TestParseStackArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      string
+		wantDepth int
+		wantFull  bool
+		wantErr   bool
+	}{
+		{name: "default", args: "", wantDepth: 10},
+		{name: "depth", args: "5", wantDepth: 5},
+		{name: "full", args: "-full", wantDepth: 10, wantFull: true},
+		{name: "depth and full", args: "-full 3", wantDepth: 3, wantFull: true},
+		{name: "not number", args: "foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			depth, full, err := parseStackArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStackArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if depth != tt.wantDepth || full != tt.wantFull {
+				t.Errorf("parseStackArgs(%q) = (%v, %v), want (%v, %v)", tt.args, depth, full, tt.wantDepth, tt.wantFull)
+			}
+		})
+	}
+}
+
+func TestParseVarArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       string
+		wantFilter string
+		wantCfg    delveapi.LoadConfig
+		wantFlag   string
+	}{
+		{name: "empty", args: "", wantFilter: "", wantCfg: delveterm.ShortLoadConfig, wantFlag: ""},
+		{name: "filter", args: "^err", wantFilter: "^err", wantCfg: delveterm.ShortLoadConfig, wantFlag: ""},
+		{name: "verbose", args: "-v", wantFilter: "", wantCfg: delveterm.LongLoadConfig, wantFlag: " -v"},
+		{name: "verbose and filter", args: "-v  ^err", wantFilter: "^err", wantCfg: delveterm.LongLoadConfig, wantFlag: " -v"},
+		{name: "filter starts with -v", args: "-verr", wantFilter: "-verr", wantCfg: delveterm.ShortLoadConfig, wantFlag: ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filter, cfg := parseVarArgs(tt.args)
+			if filter != tt.wantFilter {
+				t.Errorf("parseVarArgs(%q) filter = %q, want %q", tt.args, filter, tt.wantFilter)
+			}
+			if cfg != tt.wantCfg {
+				t.Errorf("parseVarArgs(%q) cfg = %+v, want %+v", tt.args, cfg, tt.wantCfg)
+			}
+			if got := varArgsFlag(cfg); got != tt.wantFlag {
+				t.Errorf("varArgsFlag(%+v) = %q, want %q", cfg, got, tt.wantFlag)
+			}
+		})
+	}
+}
+
+func TestBreakpointName(t *testing.T) {
+	tests := []struct {
+		name string
+		bp   *delveapi.Breakpoint
+		want string
+	}{
+		{name: "breakpoint id", bp: &delveapi.Breakpoint{ID: 1}, want: "Breakpoint 1"},
+		{name: "breakpoint name", bp: &delveapi.Breakpoint{ID: 1, Name: "loop"}, want: "Breakpoint loop"},
+		{name: "tracepoint", bp: &delveapi.Breakpoint{ID: 2, Tracepoint: true}, want: "Tracepoint 2"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := breakpointName(tt.bp); got != tt.want {
+				t.Errorf("breakpointName(%+v) = %q, want %q", tt.bp, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintSorted(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    []string
+		err     error
+		want    string
+		wantErr bool
+	}{
+		{name: "sorted", list: []string{"main.b", "main.a"}, want: "main.a\nmain.b\n"},
+		{name: "empty", list: nil, want: ""},
+		{name: "error", list: []string{"main.a"}, err: errors.New("rpc error"), want: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := printSorted(&buf, tt.list, tt.err)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("printSorted(%v) error = %v, wantErr %v", tt.list, err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("printSorted(%v) output = %q, want %q", tt.list, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelve_termSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-delve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the commands do not reach to the client
+	const script = `# comment
+
+foo
+  # indented comment
+stack x
+print
+`
+	fname := filepath.Join(dir, "script")
+	if err := ioutil.WriteFile(fname, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "relative path",
+			args: "script",
+			want: "script:3: command not available: foo\n" +
+				"script:5: depth must be a number: x\n" +
+				"script:6: not enough arguments\n",
+		},
+		{
+			name: "absolute path",
+			args: fname,
+			want: fname + ":3: command not available: foo\n" +
+				fname + ":5: depth must be a number: x\n" +
+				fname + ":6: not enough arguments\n",
+		},
+		{
+			name:    "no filename",
+			args:    "",
+			wantErr: true,
+		},
+		{
+			name:    "not exists file",
+			args:    "nosuch",
+			wantErr: true,
+		},
+	}
+	d := &Delve{}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := d.termSource(&termContext{w: &buf, dir: dir}, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("termSource(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("termSource(%q) output = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelve_runStdinCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  *termCommand
+		args string
+		want string
+	}{
+		{
+			name: "failed",
+			cmd:  &termCommand{fn: (*Delve).termPrint},
+			args: "",
+			want: "Command failed: not enough arguments\n",
+		},
+		{
+			name: "failed exec command",
+			cmd: &termCommand{fn: func(d *Delve, ctx *termContext, args string) error {
+				return errors.New("the program has exited")
+			}, exec: true},
+			want: "Command failed: the program has exited\n",
+		},
+		{
+			name: "succeeded without output",
+			cmd: &termCommand{fn: func(d *Delve, ctx *termContext, args string) error {
+				return nil
+			}, exec: true},
+			want: "",
+		},
+	}
+	d := &Delve{}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			d.runStdinCommand(&termContext{w: &buf}, tt.cmd, tt.args)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("runStdinCommand(%q) output = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
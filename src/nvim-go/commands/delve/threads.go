@@ -0,0 +1,244 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// threadLine represents a selectable line of the threads buffer.
+type threadLine struct {
+	goroutineID int
+	// frame index of the stack frame, or -1 if the line is not a frame.
+	frame int
+}
+
+// evalScope returns the selected goroutine and frame scope.
+// The GoroutineID -1 means the current goroutine.
+func (d *Delve) evalScope() delveapi.EvalScope {
+	d.scopeMu.Lock()
+	defer d.scopeMu.Unlock()
+
+	scope := d.scope
+	if scope.GoroutineID == 0 {
+		scope.GoroutineID = -1
+	}
+	return scope
+}
+
+// setScope sets the selected goroutine and frame scope.
+// The goroutineID 0 means the current goroutine.
+func (d *Delve) setScope(goroutineID, frame int) {
+	d.scopeMu.Lock()
+	defer d.scopeMu.Unlock()
+
+	d.scope = delveapi.EvalScope{GoroutineID: goroutineID, Frame: frame}
+}
+
+// resetScope resets the selected scope to the current goroutine and the top
+// frame, and records the goroutine ID of the stopped thread.
+func (d *Delve) resetScope(thread *delveapi.Thread) {
+	d.scopeMu.Lock()
+	defer d.scopeMu.Unlock()
+
+	d.scope = delveapi.EvalScope{GoroutineID: -1}
+	d.goroutineID = thread.GoroutineID
+}
+
+// byThreadID sorts the []*delveapi.Thread slice by thread ID
+type byThreadID []*delveapi.Thread
+
+func (a byThreadID) Len() int           { return len(a) }
+func (a byThreadID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byThreadID) Less(i, j int) bool { return a[i].ID < a[j].ID }
+
+// locationString returns the function name and short file path of loc.
+func locationString(loc delveapi.Location, cwd string) string {
+	fn := "?"
+	if loc.Function != nil {
+		fn = loc.Function.Name
+	}
+	return fmt.Sprintf("%s() %s:%d", fn, pathutil.ShortFilePath(loc.File, cwd), loc.Line)
+}
+
+// printThreads prints the goroutines, the stack frames of the selected
+// goroutine and the OS threads to the threads buffer.
+func (d *Delve) printThreads(cwd string) error {
+	goroutines, err := d.client.ListGoroutines()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	threads, err := d.client.ListThreads()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	sort.Sort(byGroutineID(goroutines))
+	sort.Sort(byThreadID(threads))
+
+	scope := d.evalScope()
+	selected := scope.GoroutineID
+	if selected == -1 {
+		d.scopeMu.Lock()
+		selected = d.goroutineID
+		d.scopeMu.Unlock()
+	}
+
+	var lines []string
+	var tlines []threadLine
+	add := func(line string, tl threadLine) {
+		lines = append(lines, line)
+		tlines = append(tlines, tl)
+	}
+
+	add("Goroutines", threadLine{frame: -1})
+	for _, g := range goroutines {
+		mark, status := " ", "waiting"
+		if g.ID == selected {
+			mark = "*"
+		}
+		// the goroutine which has the thread is running on the thread
+		if g.ThreadID != 0 {
+			status = "running"
+		}
+		line := fmt.Sprintf("\t%s %d [%s] %s", mark, g.ID, status, locationString(g.CurrentLoc, cwd))
+		if g.UserCurrentLoc.PC != g.CurrentLoc.PC {
+			line += " (user: " + locationString(g.UserCurrentLoc, cwd) + ")"
+		}
+		if g.GoStatementLoc.PC != 0 {
+			line += " (go: " + locationString(g.GoStatementLoc, cwd) + ")"
+		}
+		add(line, threadLine{goroutineID: g.ID, frame: -1})
+
+		if g.ID != selected {
+			continue
+		}
+		frames, err := d.client.Stacktrace(g.ID, goroutineDepth, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for i, f := range frames {
+			fmark := " "
+			if i == scope.Frame {
+				fmark = ">"
+			}
+			add(fmt.Sprintf("\t\t%s #%d %s", fmark, i, locationString(f.Location, cwd)), threadLine{goroutineID: g.ID, frame: i})
+		}
+	}
+
+	add("Threads", threadLine{frame: -1})
+	for _, th := range threads {
+		mark := " "
+		if th.GoroutineID != 0 && th.GoroutineID == selected {
+			mark = "*"
+		}
+		loc := delveapi.Location{PC: th.PC, File: th.File, Line: th.Line, Function: th.Function}
+		add(fmt.Sprintf("\t%s %d %s goroutine(%d)", mark, th.ID, locationString(loc, cwd), th.GoroutineID), threadLine{goroutineID: th.GoroutineID, frame: -1})
+	}
+
+	d.threadMu.Lock()
+	d.threadLines = tlines
+	d.threadDir = cwd
+	d.threadMu.Unlock()
+
+	buf := d.buffer[Threads].Buffer()
+	d.Nvim.SetBufferOption(buf, "modifiable", true)
+	defer d.Nvim.SetBufferOption(buf, "modifiable", false)
+
+	return errors.WithStack(d.Nvim.SetBufferLines(buf, 0, -1, true, nvimutil.ToBufferLines([]byte(strings.Join(lines, "\n")))))
+}
+
+// selectFrame selects the goroutine or stack frame of the threads buffer line,
+// and moves the cursor and pc sign to the location of the frame, and re-renders
+// the variables and the disassembly of the frame scope.
+func (d *Delve) selectFrame(v *nvim.Nvim, line int) error {
+	d.threadMu.Lock()
+	if line < 1 || line > len(d.threadLines) {
+		d.threadMu.Unlock()
+		return nil
+	}
+	tl := d.threadLines[line-1]
+	cwd := d.threadDir
+	d.threadMu.Unlock()
+
+	if tl.goroutineID == 0 || d.busy() {
+		return nil
+	}
+	frame := tl.frame
+	if frame < 0 {
+		frame = 0
+	}
+
+	frames, err := d.client.Stacktrace(tl.goroutineID, frame, nil)
+	if err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+	if frame >= len(frames) {
+		return nvimutil.ErrorWrap(v, errors.Errorf("not found the frame %d of goroutine %d", frame, tl.goroutineID))
+	}
+	d.setScope(tl.goroutineID, frame)
+
+	if err := d.showLocation(v, frames[frame].File, frames[frame].Line); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	if err := d.refreshVariables(); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	if err := d.printWatch(); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+	if err := d.printDisassembly(frames[frame].PC, cwd); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	return nvimutil.ErrorWrap(v, d.printThreads(cwd))
+}
+
+// showLocation opens the file on the source window if not opened, and moves
+// the cursor and pc sign to the line.
+func (d *Delve) showLocation(v *nvim.Nvim, file string, line int) error {
+	cw, err := v.CurrentWindow()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer v.SetCurrentWindow(cw)
+
+	if err := v.SetCurrentWindow(d.cw); err != nil {
+		return errors.WithStack(err)
+	}
+	var bufname string
+	if err := v.Call("expand", &bufname, "%:p"); err != nil {
+		return errors.WithStack(err)
+	}
+	if bufname != file {
+		if err := v.Command("silent edit " + file); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := v.SetWindowCursor(d.cw, [2]int{line, 0}); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := v.Command("silent normal zz"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	id := d.pcSign.LastID
+	if id == 0 {
+		id = 1
+	}
+	if d.pcSign.LastFile != "" {
+		d.pcSign.Unplace(v, id, d.pcSign.LastFile)
+	}
+
+	return errors.WithStack(d.pcSign.Place(v, id, line, file, false))
+}
@@ -0,0 +1,334 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+var (
+	// varLoadConfig load config of the variable inspector, which loads only the
+	// direct children of the variable. The deeper children are loaded lazily
+	// when the variable is expanded.
+	varLoadConfig = delveapi.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: 0,
+		MaxStringLen:       64,
+		MaxArrayValues:     64,
+		MaxStructFields:    -1,
+	}
+)
+
+// varNode represents a variable node of the variable inspector tree.
+type varNode struct {
+	// Name display name of the variable.
+	Name string
+	// Expr expression of the variable, which used for load the children.
+	Expr string
+	Var  *delveapi.Variable
+
+	Expanded bool
+	Children []*varNode
+}
+
+// expandable reports whether the variable has the children.
+func (n *varNode) expandable() bool {
+	v := n.Var
+	if v.Unreadable != "" {
+		return false
+	}
+
+	switch v.Kind {
+	case reflect.Ptr:
+		return len(v.Children) == 1 && v.Children[0].Addr != 0
+	case reflect.Interface:
+		return len(v.Children) == 1 && v.Children[0].Kind != reflect.Invalid
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		return len(v.Children) > 0 || v.Len > 0
+	}
+	return false
+}
+
+// loaded reports whether the children of the variable are loaded.
+func (n *varNode) loaded() bool {
+	v := n.Var
+	if v.Kind == reflect.Ptr {
+		return len(v.Children) == 1 && !v.Children[0].OnlyAddr
+	}
+	return len(v.Children) > 0
+}
+
+// buildChildren builds the child nodes from the loaded children of the variable.
+func (n *varNode) buildChildren() {
+	v := n.Var
+	n.Children = nil
+
+	switch v.Kind {
+	case reflect.Ptr:
+		n.Children = append(n.Children, &varNode{Name: "*" + n.Name, Expr: "(*" + n.Expr + ")", Var: &v.Children[0]})
+	case reflect.Interface:
+		c := &v.Children[0]
+		n.Children = append(n.Children, &varNode{Name: c.Type, Expr: fmt.Sprintf("%s.(%s)", n.Expr, c.Type), Var: c})
+	case reflect.Map:
+		// the children of map are the pairs of key and value
+		for i := 0; i+1 < len(v.Children); i += 2 {
+			key := "[" + v.Children[i].SinglelineString() + "]"
+			n.Children = append(n.Children, &varNode{Name: key, Expr: n.Expr + key, Var: &v.Children[i+1]})
+		}
+	case reflect.Array, reflect.Slice:
+		for i := range v.Children {
+			idx := fmt.Sprintf("[%d]", i)
+			n.Children = append(n.Children, &varNode{Name: idx, Expr: n.Expr + idx, Var: &v.Children[i]})
+		}
+	default:
+		for i := range v.Children {
+			c := &v.Children[i]
+			n.Children = append(n.Children, &varNode{Name: c.Name, Expr: n.Expr + "." + c.Name, Var: c})
+		}
+	}
+}
+
+// remaining returns the number of elements which are not loaded by the
+// varLoadConfig limits.
+func (n *varNode) remaining() int64 {
+	v := n.Var
+	switch v.Kind {
+	case reflect.Array, reflect.Slice:
+		return v.Len - int64(len(v.Children))
+	case reflect.Map:
+		return v.Len - int64(len(v.Children)/2)
+	}
+	return 0
+}
+
+// line returns the display line of the node.
+func (n *varNode) line(depth int) string {
+	var buf bytes.Buffer
+	buf.WriteString(strings.Repeat("\t", depth+1))
+
+	switch {
+	case !n.expandable():
+		buf.WriteString("  ")
+	case n.Expanded:
+		buf.WriteString("\u25BC ") // \u25BC: ▼
+	default:
+		buf.WriteString("\u25B6 ") // \u25B6: ▶
+	}
+	buf.WriteString(n.Name)
+	if n.Var.Type != "" {
+		buf.WriteString(" " + n.Var.Type)
+	}
+
+	switch {
+	case n.Var.Unreadable != "":
+		fmt.Fprintf(&buf, " = (unreadable %s)", n.Var.Unreadable)
+	case !n.Expanded:
+		buf.WriteString(" = " + n.Var.SinglelineString())
+	}
+
+	return buf.String()
+}
+
+// varSection represents a titled section of the variable inspector tree.
+type varSection struct {
+	Title string
+	Nodes []*varNode
+}
+
+// varTree represents the variable inspector tree.
+type varTree struct {
+	sections []*varSection
+	// expanded expressions of the expanded nodes, which keeps the expanded
+	// state across the each stops.
+	expanded map[string]bool
+	// lines nodes of the each rendered lines, nil if the line is not a variable.
+	lines []*varNode
+}
+
+// newVarTree returns the new empty varTree.
+func newVarTree() *varTree {
+	return &varTree{expanded: make(map[string]bool)}
+}
+
+// varNodes returns the top level nodes of vars.
+func varNodes(vars []delveapi.Variable) []*varNode {
+	nodes := make([]*varNode, len(vars))
+	for i := range vars {
+		nodes[i] = &varNode{Name: vars[i].Name, Expr: vars[i].Name, Var: &vars[i]}
+	}
+	return nodes
+}
+
+// evalFunc evaluates the expression of the variable.
+type evalFunc func(expr string) (*delveapi.Variable, error)
+
+// expand loads the children of n by eval if not loaded, and expands n.
+func (t *varTree) expand(n *varNode, eval evalFunc) error {
+	if !n.loaded() {
+		v, err := eval(n.Expr)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		n.Var = v
+	}
+	n.buildChildren()
+	n.Expanded = true
+	t.expanded[n.Expr] = true
+
+	return nil
+}
+
+// reset replaces the sections of t, and re-expands the nodes which were
+// expanded before the reset.
+func (t *varTree) reset(sections []*varSection, eval evalFunc) {
+	t.sections = sections
+
+	var restore func(nodes []*varNode)
+	restore = func(nodes []*varNode) {
+		for _, n := range nodes {
+			if !t.expanded[n.Expr] || !n.expandable() {
+				continue
+			}
+			if err := t.expand(n, eval); err != nil {
+				delete(t.expanded, n.Expr)
+				continue
+			}
+			restore(n.Children)
+		}
+	}
+	for _, s := range t.sections {
+		restore(s.Nodes)
+	}
+}
+
+// toggle expands or collapses the node of the idx line.
+// Reports whether the node was toggled.
+func (t *varTree) toggle(idx int, eval evalFunc) (bool, error) {
+	if idx < 0 || idx >= len(t.lines) || t.lines[idx] == nil {
+		return false, nil
+	}
+	n := t.lines[idx]
+	if !n.expandable() {
+		return false, nil
+	}
+
+	if n.Expanded {
+		n.Expanded = false
+		delete(t.expanded, n.Expr)
+		return true, nil
+	}
+
+	return true, t.expand(n, eval)
+}
+
+// render returns the lines of t, and records the node of the each lines.
+func (t *varTree) render() [][]byte {
+	var lines [][]byte
+	t.lines = nil
+
+	add := func(n *varNode, line string) {
+		lines = append(lines, []byte(line))
+		t.lines = append(t.lines, n)
+	}
+
+	var walk func(nodes []*varNode, depth int)
+	walk = func(nodes []*varNode, depth int) {
+		for _, n := range nodes {
+			add(n, n.line(depth))
+			if !n.Expanded {
+				continue
+			}
+			walk(n.Children, depth+1)
+			if rest := n.remaining(); rest > 0 {
+				add(nil, fmt.Sprintf("%s  ... +%d more", strings.Repeat("\t", depth+2), rest))
+			}
+		}
+	}
+	for _, s := range t.sections {
+		add(nil, s.Title)
+		walk(s.Nodes, 0)
+	}
+
+	return lines
+}
+
+// ----------------------------------------------------------------------------
+// variables
+
+// evalVariable evaluates the expr on the selected scope with varLoadConfig.
+func (d *Delve) evalVariable(expr string) (*delveapi.Variable, error) {
+	return d.client.EvalVariable(d.evalScope(), expr, varLoadConfig)
+}
+
+// loadVariables loads the arguments and local variables of the selected scope
+// to the variable inspector tree.
+// The caller must be hold the d.varMu lock.
+func (d *Delve) loadVariables() error {
+	scope := d.evalScope()
+	args, err := d.client.ListFunctionArgs(scope, varLoadConfig)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	locals, err := d.client.ListLocalVariables(scope, varLoadConfig)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	d.Locals = locals
+
+	if d.vars == nil {
+		d.vars = newVarTree()
+	}
+	d.vars.reset([]*varSection{
+		{Title: "Arguments", Nodes: varNodes(args)},
+		{Title: "Local Variables", Nodes: varNodes(locals)},
+	}, d.evalVariable)
+
+	return nil
+}
+
+// toggleVariable expands or collapses the variable of the context buffer line.
+func (d *Delve) toggleVariable(v *nvim.Nvim, line int) error {
+	d.varMu.Lock()
+	defer d.varMu.Unlock()
+
+	if d.vars == nil {
+		return nil
+	}
+	toggled, err := d.vars.toggle(line-1-d.varStart, d.evalVariable)
+	if err != nil || !toggled {
+		return errors.WithStack(err)
+	}
+
+	return d.renderVariables()
+}
+
+// refreshVariables reloads the variables of the selected scope, and re-renders
+// the variable inspector tree of the context buffer.
+func (d *Delve) refreshVariables() error {
+	d.varMu.Lock()
+	defer d.varMu.Unlock()
+
+	if err := d.loadVariables(); err != nil {
+		return err
+	}
+	return d.renderVariables()
+}
+
+// renderVariables renders the variable inspector tree to the context buffer.
+// The caller must be hold the d.varMu lock.
+func (d *Delve) renderVariables() error {
+	buf := d.buffer[Context].Buffer()
+	d.Nvim.SetBufferOption(buf, "modifiable", true)
+	defer d.Nvim.SetBufferOption(buf, "modifiable", false)
+
+	return errors.WithStack(d.Nvim.SetBufferLines(buf, d.varStart, -1, true, d.vars.render()))
+}
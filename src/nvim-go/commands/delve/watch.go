@@ -0,0 +1,181 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"strings"
+
+	"nvim-go/nvimutil"
+
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// watchValue returns the value of the watch expression for the comparison.
+func watchValue(v *delveapi.Variable) string {
+	if v.Unreadable != "" {
+		return v.Unreadable
+	}
+	return v.SinglelineString()
+}
+
+// ----------------------------------------------------------------------------
+// watch
+
+func (d *Delve) cmdWatch(v *nvim.Nvim, args []string) {
+	go d.watch(v, args)
+}
+
+// watch adds the expression of args to the watch expressions, and prints the
+// watch buffer if the debugging session is running.
+func (d *Delve) watch(v *nvim.Nvim, args []string) error {
+	expr := strings.Join(args, " ")
+
+	d.watchMu.Lock()
+	exists := false
+	for _, w := range d.watches {
+		if w == expr {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		d.watches = append(d.watches, expr)
+	}
+	d.watchMu.Unlock()
+
+	return nvimutil.ErrorWrap(v, d.printWatch())
+}
+
+// ----------------------------------------------------------------------------
+// unwatch
+
+func (d *Delve) cmdUnwatch(v *nvim.Nvim, args []string) {
+	go d.unwatch(v, args)
+}
+
+// unwatch removes the expression of args from the watch expressions, or
+// removes the all watch expressions if args is empty.
+func (d *Delve) unwatch(v *nvim.Nvim, args []string) error {
+	expr := strings.Join(args, " ")
+
+	d.watchMu.Lock()
+	if expr == "" {
+		d.watches = nil
+		d.watchValues = nil
+	} else {
+		found := false
+		for i, w := range d.watches {
+			if w == expr {
+				d.watches = append(d.watches[:i], d.watches[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			d.watchMu.Unlock()
+			return nvimutil.ErrorWrap(v, errors.Errorf("not found the watch expression: %s", expr))
+		}
+	}
+	delete(d.watchValues, expr)
+	d.watchMu.Unlock()
+
+	return nvimutil.ErrorWrap(v, d.printWatch())
+}
+
+// WatchCompletion returns the watch expressions for the DlvUnwatch command completion.
+func (d *Delve) WatchCompletion(v *nvim.Nvim) ([]string, error) {
+	d.watchMu.Lock()
+	defer d.watchMu.Unlock()
+
+	return append([]string{}, d.watches...), nil
+}
+
+// ----------------------------------------------------------------------------
+// watch buffer
+
+// printWatch evaluates the watch expressions and prints the results to the
+// watch buffer. The changed values from the last evaluation are highlighted
+// with fade out.
+func (d *Delve) printWatch() error {
+	d.watchMu.Lock()
+	defer d.watchMu.Unlock()
+
+	// evaluates at the next stop if the program is running
+	if !d.running() || d.busy() || d.buffer[Watch] == nil {
+		return nil
+	}
+
+	nodes := make([]*varNode, len(d.watches))
+	for i, expr := range d.watches {
+		val, err := d.evalVariable(expr)
+		if err != nil {
+			val = &delveapi.Variable{Name: expr, Unreadable: errors.Cause(err).Error()}
+		}
+		nodes[i] = &varNode{Name: expr, Expr: expr, Var: val}
+	}
+	if d.watchTree == nil {
+		d.watchTree = newVarTree()
+	}
+	d.watchTree.reset([]*varSection{{Title: "Watch Expressions", Nodes: nodes}}, d.evalVariable)
+
+	if err := d.renderWatch(); err != nil {
+		return err
+	}
+
+	if d.watchValues == nil {
+		d.watchValues = make(map[string]string)
+	}
+	for i, n := range d.watchTree.lines {
+		if n == nil || !isWatchNode(n, nodes) {
+			continue
+		}
+		val := watchValue(n.Var)
+		if prev, ok := d.watchValues[n.Expr]; ok && prev != val {
+			fade := nvimutil.NewFader(d.Nvim, d.buffer[Watch].Buffer(), "delveFade", i, i, 0, -1, 80)
+			go fade.FadeOut()
+		}
+		d.watchValues[n.Expr] = val
+	}
+
+	return nil
+}
+
+// isWatchNode reports whether n is the top level node of the watch expressions.
+func isWatchNode(n *varNode, nodes []*varNode) bool {
+	for _, w := range nodes {
+		if n == w {
+			return true
+		}
+	}
+	return false
+}
+
+// renderWatch renders the watch tree to the watch buffer.
+// The caller must be hold the d.watchMu lock.
+func (d *Delve) renderWatch() error {
+	buf := d.buffer[Watch].Buffer()
+	d.Nvim.SetBufferOption(buf, "modifiable", true)
+	defer d.Nvim.SetBufferOption(buf, "modifiable", false)
+
+	return errors.WithStack(d.Nvim.SetBufferLines(buf, 0, -1, true, d.watchTree.render()))
+}
+
+// toggleWatch expands or collapses the watch expression of the watch buffer line.
+func (d *Delve) toggleWatch(v *nvim.Nvim, line int) error {
+	d.watchMu.Lock()
+	defer d.watchMu.Unlock()
+
+	if d.watchTree == nil {
+		return nil
+	}
+	toggled, err := d.watchTree.toggle(line-1, d.evalVariable)
+	if err != nil || !toggled {
+		return errors.WithStack(err)
+	}
+
+	return d.renderWatch()
+}
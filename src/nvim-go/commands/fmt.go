@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
 	"nvim-go/nvimutil"
 
 	"github.com/neovim/go-client/nvim"
@@ -32,8 +33,8 @@ func (c *Commands) cmdFmt(dir string) {
 		case error:
 			nvimutil.ErrorWrap(c.Nvim, e)
 		case []*nvim.QuickfixError:
-			c.ctx.Errlist["Fmt"] = e
-			nvimutil.ErrorList(c.Nvim, c.ctx.Errlist, true)
+			c.ctx.SetErrlist("Fmt", e)
+			nvimutil.ErrorList(c.Nvim, c.ctx.CopyErrlist(), true)
 		}
 	}()
 }
@@ -41,7 +42,6 @@ func (c *Commands) cmdFmt(dir string) {
 // Fmt format to the current buffer source uses gofmt behavior.
 func (c *Commands) Fmt(dir string) interface{} {
 	defer nvimutil.Profile(time.Now(), "GoFmt")
-	defer c.ctx.SetContext(dir)()
 
 	var (
 		b nvim.Buffer
@@ -67,13 +67,13 @@ func (c *Commands) Fmt(dir string) interface{} {
 		return errors.WithStack(errors.New("invalid value of go#fmt#mode option"))
 	}
 
-	buf, formatErr := imports.Process("", nvimutil.ToByteSlice(in), &importsOptions)
-	if formatErr != nil {
-		bufName, err := c.Nvim.BufferName(b)
-		if err != nil {
-			return errors.WithStack(err)
-		}
+	bufName, err := c.Nvim.BufferName(b)
+	if err != nil {
+		return errors.WithStack(err)
+	}
 
+	buf, formatErr := formatSource(context.NewBuildContext(dir), bufName, nvimutil.ToByteSlice(in))
+	if formatErr != nil {
 		var errlist []*nvim.QuickfixError
 		if e, ok := formatErr.(scanner.Error); ok {
 			errlist = append(errlist, &nvim.QuickfixError{
@@ -95,7 +95,7 @@ func (c *Commands) Fmt(dir string) interface{} {
 
 		return errlist
 	}
-	delete(c.ctx.Errlist, "Fmt")
+	c.ctx.DeleteErrlist("Fmt")
 
 	out := nvimutil.ToBufferLines(bytes.TrimSuffix(buf, []byte{'\n'}))
 	minUpdate(c.Nvim, b, in, out)
@@ -111,6 +111,16 @@ func (c *Commands) Fmt(dir string) interface{} {
 	return c.Nvim.Command("noautocmd write")
 }
 
+// formatSource formats src of the filename with the importsOptions.
+// The missing imports are resolved from the GOPATH of bctxt, such as the gb
+// project src and vendor.
+func formatSource(bctxt *context.BuildContext, filename string, src []byte) (buf []byte, err error) {
+	bctxt.WithDefault(func() {
+		buf, err = imports.Process(filename, src, &importsOptions)
+	})
+	return buf, err
+}
+
 func minUpdate(v *nvim.Nvim, b nvim.Buffer, in [][]byte, out [][]byte) error {
 	// Find matching head lines.
 	n := len(out)
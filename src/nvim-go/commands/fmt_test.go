@@ -6,6 +6,10 @@ package commands
 
 import (
 	"bytes"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -83,6 +87,48 @@ func TestCommands_Fmt(t *testing.T) {
 	}
 }
 
+func TestFormatSource(t *testing.T) {
+	// gb project layout which has the package only in the vendor directory
+	root, err := ioutil.TempDir("", "nvim-go-test-fmt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	files := map[string]string{
+		filepath.Join("src", "cmd", "gbfmt", "main.go"):                 "package main\n",
+		filepath.Join("vendor", "src", "gbfmtvendor", "gbfmtvendor.go"): "package gbfmtvendor\n\nfunc Hello() {}\n",
+	}
+	for name, data := range files {
+		fname := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fname, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config.FmtMode = "goimports"
+	dir := filepath.Join(root, "src", "cmd", "gbfmt")
+	bctxt := context.NewBuildContext(dir)
+	if bctxt.Build.Tool != "gb" {
+		t.Fatalf("Build.Tool = %v, want gb", bctxt.Build.Tool)
+	}
+	original := build.Default.GOPATH
+
+	src := []byte("package main\n\nfunc main() { gbfmtvendor.Hello() }\n")
+	got, err := formatSource(bctxt, filepath.Join(dir, "main.go"), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(got, []byte(`import "gbfmtvendor"`)) {
+		t.Errorf("formatSource() = %s, want the gbfmtvendor import", got)
+	}
+	if build.Default.GOPATH != original {
+		t.Errorf("formatSource() changed build.Default.GOPATH to %v", build.Default.GOPATH)
+	}
+}
+
 var minUpdateTests = []struct {
 	in  string
 	out string
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
 	"nvim-go/nvimutil"
 
 	"github.com/cweill/gotests/gotests/process"
@@ -30,7 +31,6 @@ func (c *Commands) cmdGenerateTest(args []string, ranges [2]int, bang bool, dir
 // functions.
 func (c *Commands) GenerateTest(args []string, ranges [2]int, bang bool, dir string) error {
 	defer nvimutil.Profile(time.Now(), "GenerateTest")
-	defer c.ctx.SetContext(filepath.Dir(dir))()
 
 	b, err := c.Nvim.CurrentBuffer()
 	if err != nil {
@@ -91,7 +91,10 @@ func (c *Commands) GenerateTest(args []string, ranges [2]int, bang bool, dir str
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	process.Run(w, args, opt)
+	// gotests formats the generated tests with goimports
+	context.NewBuildContext(dir).WithDefault(func() {
+		process.Run(w, args, opt)
+	})
 
 	w.Close()
 	os.Stdout = oldStdout
@@ -17,12 +17,15 @@ import (
 	"go/token"
 	"go/types"
 	"log"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
 	"nvim-go/internal/guru"
 	"nvim-go/nvimutil"
 	"nvim-go/pathutil"
@@ -65,7 +68,7 @@ func (c *Commands) Guru(args []string, eval *funcGuruEval) interface{} {
 	}
 
 	dir := filepath.Dir(eval.File)
-	defer c.ctx.SetContext(dir)()
+	bctxt := context.NewBuildContext(dir)
 
 	defer func() (err error) {
 		if r := recover(); r != nil {
@@ -85,7 +88,11 @@ func (c *Commands) Guru(args []string, eval *funcGuruEval) interface{} {
 		return errors.WithStack(err)
 	}
 
-	guruContext := &build.Default
+	guruContext := &bctxt.Context
+	if bctxt.Build.Tool == "mod" {
+		// the GOPATH mode build.Context can't find the module outside of the GOPATH
+		guruContext = moduleContext(guruContext, &bctxt.Build)
+	}
 
 	// https://github.com/golang/tools/blob/master/cmd/guru/main.go
 	if eval.Modified != 0 {
@@ -129,18 +136,24 @@ func (c *Commands) Guru(args []string, eval *funcGuruEval) interface{} {
 		return c.Nvim.Command(`lclose | normal! zz`)
 	}
 
-	var scope string
-	switch c.ctx.Build.Tool {
+	switch bctxt.Build.Tool {
 	case "go":
-		pkgID, err := pathutil.PackageID(dir)
+		pkgID, err := pathutil.PackageIDContext(&bctxt.Context, dir)
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		scope = pkgID
+		query.Scope = append(query.Scope, filepath.Join(pkgID, "..."))
 	case "gb":
-		scope = pathutil.GbProjectName(c.ctx.Build.ProjectRoot)
+		query.Scope = append(query.Scope, filepath.Join(pathutil.GbProjectName(bctxt.Build.ProjectRoot), "..."))
+	case "mod":
+		// the "..." pattern is expanded only in the GOPATH, so lists the
+		// module packages from the disk
+		scope, err := modulePackageIDs(&bctxt.Build)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		query.Scope = append(query.Scope, scope...)
 	}
-	query.Scope = append(query.Scope, filepath.Join(scope, "..."))
 
 	var (
 		outputMu sync.Mutex
@@ -188,6 +201,82 @@ func (c *Commands) Guru(args []string, eval *funcGuruEval) interface{} {
 	return nvimutil.OpenLoclist(c.Nvim, w, loclist, keepCursor)
 }
 
+// modulePackageIDs returns the package IDs of the module b.
+func modulePackageIDs(b *context.Build) ([]string, error) {
+	dirs, err := pathutil.ModulePackageDirs(b.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgIDs := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		pkgID, err := pathutil.ModulePackageID(b.ProjectRoot, b.ModulePath, dir)
+		if err != nil {
+			return nil, err
+		}
+		pkgIDs = append(pkgIDs, pkgID)
+	}
+	return pkgIDs, nil
+}
+
+// moduleContext returns the copy of ctxt which finds the packages of the module
+// b even if outside of the GOPATH. The module is mapped to the src directory
+// of the virtual GOPATH entry, but the package directories are the real path
+// so that the results point to the module files.
+func moduleContext(ctxt *build.Context, b *context.Build) *build.Context {
+	mctxt := *ctxt
+	if b.ModulePath == "" {
+		return &mctxt
+	}
+
+	// the virtual GOPATH entry which is never created
+	vroot := filepath.Join(b.ProjectRoot, ".nvim-go-gopath")
+	vsrc := filepath.Join(vroot, "src")
+	vmod := filepath.Join(vsrc, filepath.FromSlash(b.ModulePath))
+	mctxt.GOPATH = strings.Join(append([]string{vroot}, filepath.SplitList(ctxt.GOPATH)...), string(filepath.ListSeparator))
+
+	mctxt.JoinPath = func(elem ...string) string {
+		p := filepath.Join(elem...)
+		if rel, ok := hasSubdir(vmod, p); ok {
+			return filepath.Join(b.ProjectRoot, rel)
+		}
+		return p
+	}
+	mctxt.IsDir = func(p string) bool {
+		if p == vsrc {
+			return true
+		}
+		fi, err := os.Stat(p)
+		return err == nil && fi.IsDir()
+	}
+	mctxt.HasSubdir = func(root, dir string) (string, bool) {
+		if filepath.Clean(root) == vsrc {
+			rel, ok := hasSubdir(b.ProjectRoot, dir)
+			if !ok {
+				return "", false
+			}
+			return path.Join(b.ModulePath, rel), true
+		}
+		rel, ok := hasSubdir(root, dir)
+		return rel, ok && rel != "."
+	}
+
+	return &mctxt
+}
+
+// hasSubdir reports whether dir is lexically the root or its subdirectory,
+// and returns the slash separated relative path from the root.
+func hasSubdir(root, dir string) (string, bool) {
+	root, dir = filepath.Clean(root), filepath.Clean(dir)
+	if dir == root {
+		return ".", true
+	}
+	if !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(dir[len(root)+1:]), true
+}
+
 type fallback struct {
 	Obj *serial.Definition
 	Err error
@@ -0,0 +1,99 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nvim-go/context"
+
+	"golang.org/x/tools/go/buildutil"
+)
+
+func TestModuleContext(t *testing.T) {
+	root, err := ioutil.TempDir("", "nvim-go-guru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// the module is outside of the GOPATH
+	modroot := filepath.Join(root, "mod")
+	files := map[string]string{
+		"go.mod":          "module foo.org/mod\n",
+		"mod.go":          "package mod\n",
+		"sub/sub.go":      "package sub\n\nimport _ \"foo.org/mod\"\n",
+		"sub/sub_test.go": "package sub\n",
+	}
+	for name, data := range files {
+		fname := filepath.Join(modroot, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fname, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctxt := build.Default
+	ctxt.GOPATH = filepath.Join(root, "gopath")
+	mctxt := moduleContext(&ctxt, &context.Build{Tool: "mod", ProjectRoot: modroot, ModulePath: "foo.org/mod"})
+
+	tests := []struct {
+		name    string
+		pkgID   string
+		wantDir string
+		wantErr bool
+	}{
+		{
+			name:    "module root package",
+			pkgID:   "foo.org/mod",
+			wantDir: modroot,
+		},
+		{
+			name:    "module sub package",
+			pkgID:   "foo.org/mod/sub",
+			wantDir: filepath.Join(modroot, "sub"),
+		},
+		{
+			name:    "not exists package",
+			pkgID:   "foo.org/mod/nosuch",
+			wantErr: true,
+		},
+		{
+			name:    "standard package",
+			pkgID:   "fmt",
+			wantDir: filepath.Join(ctxt.GOROOT, "src", "fmt"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, err := mctxt.Import(tt.pkgID, modroot, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Import(%v) error = %v, wantErr %v", tt.pkgID, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if pkg.Dir != tt.wantDir {
+				t.Errorf("Import(%v).Dir = %v, want %v", tt.pkgID, pkg.Dir, tt.wantDir)
+			}
+		})
+	}
+
+	// guru finds the package of the query position by the file name
+	pkg, err := buildutil.ContainingPackage(mctxt, modroot, filepath.Join(modroot, "sub", "sub.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pkg.ImportPath, "foo.org/mod/sub"; got != want {
+		t.Errorf("ContainingPackage().ImportPath = %v, want %v", got, want)
+	}
+}
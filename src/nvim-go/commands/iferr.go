@@ -8,7 +8,6 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
-	"go/build"
 	"go/format"
 	"go/parser"
 	"go/token"
@@ -17,6 +16,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"nvim-go/context"
 	"nvim-go/nvimutil"
 
 	astmanip "github.com/motemen/go-astmanip"
@@ -34,7 +34,7 @@ func (c *Commands) Iferr(file string) error {
 	defer nvimutil.Profile(time.Now(), "GoIferr")
 
 	dir := filepath.Dir(file)
-	defer c.ctx.SetContext(dir)()
+	bctxt := context.NewBuildContext(dir)
 
 	b, err := c.Nvim.CurrentBuffer()
 	if err != nil {
@@ -49,7 +49,7 @@ func (c *Commands) Iferr(file string) error {
 	conf := loader.Config{
 		ParserMode:  parser.ParseComments,
 		TypeChecker: types.Config{FakeImportC: true, DisableUnusedImportCheck: true},
-		Build:       &build.Default,
+		Build:       &bctxt.Context,
 		Cwd:         dir,
 		AllowErrors: true,
 	}
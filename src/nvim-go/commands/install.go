@@ -0,0 +1,125 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/context"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// CmdInstallEval struct type for Eval of GoInstall command.
+type CmdInstallEval struct {
+	Cwd  string `msgpack:",array"`
+	File string
+}
+
+func (c *Commands) cmdInstall(bang bool, eval *CmdInstallEval) {
+	go func() {
+		err := c.Install(bang, eval)
+
+		switch e := err.(type) {
+		case error:
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case []*nvim.QuickfixError:
+			c.ctx.SetErrlist("Install", e)
+			nvimutil.ErrorList(c.Nvim, c.ctx.CopyErrlist(), true)
+		}
+	}()
+}
+
+// Install installs the current buffers package use compile tool that
+// determined from the package directory structure.
+// If bang is true, installs all packages under the project root.
+func (c *Commands) Install(bang bool, eval *CmdInstallEval) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoInstall")
+	dir := filepath.Dir(eval.File)
+	bctxt := context.NewBuildContext(dir)
+
+	cmd, err := c.installCmd(bctxt, bang, dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if installErr := cmd.Run(); installErr != nil {
+		if _, ok := installErr.(*exec.ExitError); ok {
+			errlist, err := nvimutil.ParseError(stderr.Bytes(), eval.Cwd, bctxt)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			return errlist
+		}
+		return installErr
+	}
+
+	// Install succeeded, clean up the Errlist
+	c.ctx.DeleteErrlist("Install")
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoInstall", fmt.Sprintf("compiler: %s", bctxt.Build.Tool))
+}
+
+// installCmd returns the *exec.Cmd corresponding to the install of compile tool.
+func (c *Commands) installCmd(bctxt *context.BuildContext, bang bool, dir string) (*exec.Cmd, error) {
+	bin, err := exec.LookPath(bctxt.Build.Command())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	args := []string{}
+	if len(config.BuildFlags) > 0 {
+		args = append(args, config.BuildFlags...)
+	}
+
+	cmd := exec.Command(bin)
+	cmd.Dir = dir
+	cmd.Env = bctxt.Env
+
+	switch bctxt.Build.Tool {
+	case "go":
+		cmd.Args = append(cmd.Args, "install")
+		if bang {
+			// the ProjectRoot of go project is the package directory, so
+			// installs the all packages under the repository root
+			cmd.Dir = bctxt.Build.ProjectRoot
+			if bctxt.Build.VCSRoot != "" {
+				cmd.Dir = bctxt.Build.VCSRoot
+			}
+			args = append(args, "./...")
+		}
+	case "mod":
+		cmd.Args = append(cmd.Args, "install")
+		if bang {
+			cmd.Dir = bctxt.Build.ProjectRoot
+			args = append(args, "./...")
+		}
+	case "gb":
+		// gb build installs the binaries to the $PROJECT/bin directory
+		cmd.Args = append(cmd.Args, "build")
+		cmd.Dir = bctxt.Build.ProjectRoot
+		if !bang {
+			pkgID, err := pathutil.PackageIDContext(&bctxt.Context, dir)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			args = append(args, pkgID)
+		}
+	}
+
+	cmd.Args = append(cmd.Args, args...)
+
+	return cmd, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"nvim-go/context"
+)
+
+func TestCommands_installCmd(t *testing.T) {
+	gobinary, err := exec.LookPath("go")
+	if err != nil {
+		t.Error(err)
+	}
+
+	type args struct {
+		bctxt *context.BuildContext
+		bang  bool
+		dir   string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantDir string
+		wantErr bool
+	}{
+		{
+			name: "astdump (go install)",
+			args: args{
+				bctxt: &context.BuildContext{Build: context.Build{Tool: "go", ProjectRoot: astdump}},
+				dir:   astdump,
+			},
+			want:    []string{gobinary, "install"},
+			wantDir: astdump,
+			wantErr: false,
+		},
+		{
+			name: "astdump (go install with bang)",
+			args: args{
+				bctxt: &context.BuildContext{Build: context.Build{Tool: "go", ProjectRoot: astdump}},
+				bang:  true,
+				dir:   astdump,
+			},
+			want:    []string{gobinary, "install", "./..."},
+			wantDir: astdump,
+			wantErr: false,
+		},
+		{
+			name: "astdump (go install with bang in repository)",
+			args: args{
+				bctxt: &context.BuildContext{Build: context.Build{Tool: "go", ProjectRoot: astdump, VCSRoot: testdataPath}},
+				bang:  true,
+				dir:   astdump,
+			},
+			want:    []string{gobinary, "install", "./..."},
+			wantDir: testdataPath,
+			wantErr: false,
+		},
+		{
+			name: "mod (go install with bang)",
+			args: args{
+				bctxt: &context.BuildContext{Build: context.Build{Tool: "mod", ProjectRoot: testdataPath}},
+				bang:  true,
+				dir:   astdump,
+			},
+			want:    []string{gobinary, "install", "./..."},
+			wantDir: testdataPath,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommands(nil, context.NewContext())
+
+			got, err := c.installCmd(tt.args.bctxt, tt.args.bang, tt.args.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Commands.installCmd(%v, %v) error = %v, wantErr %v", tt.args.bang, tt.args.dir, err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got.Args, tt.want) {
+				t.Errorf("Commands.installCmd(%v, %v) = %v, want %v", tt.args.bang, tt.args.dir, got.Args, tt.want)
+			}
+			if got.Dir != tt.wantDir {
+				t.Errorf("Commands.installCmd(%v, %v).Dir = %v, want %v", tt.args.bang, tt.args.dir, got.Dir, tt.wantDir)
+			}
+		})
+	}
+}
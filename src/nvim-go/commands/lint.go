@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
 	"nvim-go/nvimutil"
 	"nvim-go/pathutil"
 
@@ -26,15 +27,15 @@ import (
 
 func (c *Commands) cmdLint(v *nvim.Nvim, args []string, file string) {
 	// Cleanup error list
-	delete(c.ctx.Errlist, "Lint")
+	c.ctx.DeleteErrlist("Lint")
 
 	go func() {
 		errlist, err := c.Lint(args, file)
 		if err != nil {
 			nvimutil.ErrorWrap(c.Nvim, err)
 		}
-		c.ctx.Errlist["Lint"] = errlist
-		nvimutil.ErrorList(c.Nvim, c.ctx.Errlist, true)
+		c.ctx.SetErrlist("Lint", errlist)
+		nvimutil.ErrorList(c.Nvim, c.ctx.CopyErrlist(), true)
 	}()
 }
 
@@ -50,8 +51,8 @@ const (
 func (c *Commands) Lint(args []string, file string) ([]*nvim.QuickfixError, error) {
 	defer nvimutil.Profile(time.Now(), "GoLint")
 	dir := filepath.Dir(file)
-	defer c.ctx.SetContext(dir)()
-	buildContext = build.Default
+	bctxt := context.NewBuildContext(dir)
+	ctxt := &bctxt.Context
 
 	var (
 		errlist []*nvim.QuickfixError
@@ -61,21 +62,38 @@ func (c *Commands) Lint(args []string, file string) ([]*nvim.QuickfixError, erro
 	case len(args) == 0:
 		switch lintMode(config.GolintMode) {
 		case current:
-			errlist, err = c.lintDir(dir)
+			errlist, err = c.lintDir(ctxt, dir)
 		case root:
+			// the module may be outside of the GOPATH, so lints the package
+			// directories on disk instead of the import paths
+			if bctxt.Build.Tool == "mod" {
+				dirs, err := pathutil.ModulePackageDirs(bctxt.Build.ProjectRoot)
+				if err != nil {
+					return nil, err
+				}
+				for _, dir := range dirs {
+					errors, err := c.lintDir(ctxt, dir)
+					if err != nil {
+						return nil, err
+					}
+					errlist = append(errlist, errors...)
+				}
+				break
+			}
+
 			var rootDir string
-			switch c.ctx.Build.Tool {
+			switch bctxt.Build.Tool {
 			case "go":
-				root, err := pathutil.PackageID(c.ctx.Build.ProjectRoot)
+				root, err := pathutil.PackageIDContext(ctxt, bctxt.Build.ProjectRoot)
 				if err != nil {
 					return nil, errors.WithStack(err)
 				}
 				rootDir = root
 			case "gb":
-				rootDir = filepath.Base(c.ctx.Build.ProjectRoot)
+				rootDir = filepath.Base(bctxt.Build.ProjectRoot)
 			}
-			for _, pkgname := range importPaths([]string{rootDir + "/..."}) {
-				errors, err := c.lintPackage(pkgname)
+			for _, pkgname := range importPaths(ctxt, []string{rootDir + "/..."}) {
+				errors, err := c.lintPackage(ctxt, pkgname)
 				if err != nil {
 					return nil, err
 				}
@@ -90,12 +108,12 @@ func (c *Commands) Lint(args []string, file string) ([]*nvim.QuickfixError, erro
 		}
 		switch {
 		case pathutil.IsDir(path):
-			errlist, err = c.lintDir(path)
+			errlist, err = c.lintDir(ctxt, path)
 		case pathutil.IsExist(path):
 			errlist, err = c.lintFiles(path)
 		case !pathutil.IsDir(path) || !pathutil.IsExist(path):
-			for _, pkgname := range importPaths(args) {
-				errlist, err = c.lintPackage(pkgname)
+			for _, pkgname := range importPaths(ctxt, args) {
+				errlist, err = c.lintPackage(ctxt, pkgname)
 			}
 		}
 
@@ -175,13 +193,13 @@ func contain(s string, ignore []string) bool {
 	return false
 }
 
-func (c *Commands) lintDir(dirname string) ([]*nvim.QuickfixError, error) {
-	pkg, err := build.ImportDir(dirname, 0)
+func (c *Commands) lintDir(ctxt *build.Context, dirname string) ([]*nvim.QuickfixError, error) {
+	pkg, err := ctxt.ImportDir(dirname, 0)
 	return c.lintImportedPackage(pkg, err)
 }
 
-func (c *Commands) lintPackage(pkgname string) ([]*nvim.QuickfixError, error) {
-	pkg, err := build.Import(pkgname, ".", 0)
+func (c *Commands) lintPackage(ctxt *build.Context, pkgname string) ([]*nvim.QuickfixError, error) {
+	pkg, err := ctxt.Import(pkgname, ".", 0)
 	return c.lintImportedPackage(pkg, err)
 }
 
@@ -211,8 +229,6 @@ func (c *Commands) lintImportedPackage(pkg *build.Package, err error) ([]*nvim.Q
 // ----------------------------------------------------------------------------
 // The below code is carried from github.com/golang/lint/golint/import.go
 
-var buildContext = build.Default
-
 var (
 	goroot    = filepath.Clean(runtime.GOROOT())
 	gorootSrc = filepath.Join(goroot, "src")
@@ -220,7 +236,7 @@ var (
 
 // importPathsNoDotExpansion returns the import paths to use for the given
 // command line, but it does no ... expansion.
-func importPathsNoDotExpansion(args []string) []string {
+func importPathsNoDotExpansion(ctxt *build.Context, args []string) []string {
 	if len(args) == 0 {
 		return []string{"."}
 	}
@@ -243,7 +259,7 @@ func importPathsNoDotExpansion(args []string) []string {
 			a = pathPkg.Clean(a)
 		}
 		if a == "all" || a == "std" {
-			out = append(out, allPackages(a)...)
+			out = append(out, allPackages(ctxt, a)...)
 			continue
 		}
 		out = append(out, a)
@@ -252,15 +268,15 @@ func importPathsNoDotExpansion(args []string) []string {
 }
 
 // importPaths returns the import paths to use for the given command line.
-func importPaths(args []string) []string {
-	args = importPathsNoDotExpansion(args)
+func importPaths(ctxt *build.Context, args []string) []string {
+	args = importPathsNoDotExpansion(ctxt, args)
 	var out []string
 	for _, a := range args {
 		if strings.Contains(a, "...") {
 			if build.IsLocalImport(a) {
-				out = append(out, allPackagesInFS(a)...)
+				out = append(out, allPackagesInFS(ctxt, a)...)
 			} else {
-				out = append(out, allPackages(a)...)
+				out = append(out, allPackages(ctxt, a)...)
 			}
 			continue
 		}
@@ -321,15 +337,15 @@ func treeCanMatchPattern(pattern string) func(name string) bool {
 // under the $GOPATH directories and $GOROOT matching pattern.
 // The pattern is either "all" (all packages), "std" (standard packages)
 // or a path including "...".
-func allPackages(pattern string) []string {
-	pkgs := matchPackages(pattern)
+func allPackages(ctxt *build.Context, pattern string) []string {
+	pkgs := matchPackages(ctxt, pattern)
 	if len(pkgs) == 0 {
 		// fmt.Fprintf(os.Stderr, "warning: %q matched no packages\n", pattern)
 	}
 	return pkgs
 }
 
-func matchPackages(pattern string) []string {
+func matchPackages(ctxt *build.Context, pattern string) []string {
 	match := func(string) bool { return true }
 	treeCanMatch := func(string) bool { return true }
 	if pattern != "all" && pattern != "std" {
@@ -340,7 +356,7 @@ func matchPackages(pattern string) []string {
 	have := map[string]bool{
 		"builtin": true, // ignore pseudo-package that exists only for documentation
 	}
-	if !buildContext.CgoEnabled {
+	if !ctxt.CgoEnabled {
 		have["runtime/cgo"] = true // ignore during walk
 	}
 	var pkgs []string
@@ -369,7 +385,7 @@ func matchPackages(pattern string) []string {
 		if !match(name) {
 			return nil
 		}
-		_, err = buildContext.ImportDir(path, 0)
+		_, err = ctxt.ImportDir(path, 0)
 		if err != nil {
 			if _, noGo := err.(*build.NoGoError); !noGo {
 				// log.Print(err)
@@ -380,7 +396,7 @@ func matchPackages(pattern string) []string {
 		return nil
 	})
 
-	for _, src := range buildContext.SrcDirs() {
+	for _, src := range ctxt.SrcDirs() {
 		if (pattern == "std" || pattern == "cmd") && src != gorootSrc {
 			continue
 		}
@@ -416,7 +432,7 @@ func matchPackages(pattern string) []string {
 			if !match(name) {
 				return nil
 			}
-			_, err = buildContext.ImportDir(path, 0)
+			_, err = ctxt.ImportDir(path, 0)
 			if err != nil {
 				if _, noGo := err.(*build.NoGoError); noGo {
 					return nil
@@ -432,15 +448,15 @@ func matchPackages(pattern string) []string {
 // allPackagesInFS is like allPackages but is passed a pattern
 // beginning ./ or ../, meaning it should scan the tree rooted
 // at the given directory.  There are ... in the pattern too.
-func allPackagesInFS(pattern string) []string {
-	pkgs := matchPackagesInFS(pattern)
+func allPackagesInFS(ctxt *build.Context, pattern string) []string {
+	pkgs := matchPackagesInFS(ctxt, pattern)
 	if len(pkgs) == 0 {
 		// fmt.Fprintf(os.Stderr, "warning: %q matched no packages\n", pattern)
 	}
 	return pkgs
 }
 
-func matchPackagesInFS(pattern string) []string {
+func matchPackagesInFS(ctxt *build.Context, pattern string) []string {
 	// Find directory to begin the scan.
 	// Could be smarter but this one optimization
 	// is enough for now, since ... is usually at the
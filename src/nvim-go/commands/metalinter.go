@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
 	"nvim-go/nvimutil"
 	"nvim-go/pathutil"
 
@@ -35,7 +36,7 @@ type metalinterResult struct {
 // Metalinter lint the Go sources from current buffer's package use gometalinter tool.
 func (c *Commands) Metalinter(cwd string) error {
 	defer nvimutil.Profile(time.Now(), "GoMetaLinter")
-	defer c.ctx.SetContext(cwd)()
+	bctxt := context.NewBuildContext(cwd)
 
 	var (
 		loclist []*nvim.QuickfixError
@@ -49,11 +50,11 @@ func (c *Commands) Metalinter(cwd string) error {
 	}
 
 	var args []string
-	switch c.ctx.Build.Tool {
+	switch bctxt.Build.Tool {
 	case "go":
 		args = append(args, cwd+"/...")
-	case "gb":
-		args = append(args, c.ctx.Build.ProjectRoot+"/...")
+	case "gb", "mod":
+		args = append(args, bctxt.Build.ProjectRoot+"/...")
 	}
 	args = append(args, []string{"--json", "--disable-all", "--deadline", config.MetalinterDeadline}...)
 
@@ -67,6 +68,7 @@ func (c *Commands) Metalinter(cwd string) error {
 	}
 
 	cmd := exec.Command("gometalinter", args...)
+	cmd.Env = bctxt.Env
 	stdout, err := cmd.Output()
 	cmd.Run()
 
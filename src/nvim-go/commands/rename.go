@@ -6,7 +6,6 @@ package commands
 
 import (
 	"fmt"
-	"go/build"
 	"io/ioutil"
 	"log"
 	"os"
@@ -14,6 +13,7 @@ import (
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
 	"nvim-go/nvimutil"
 
 	"github.com/neovim/go-client/nvim"
@@ -37,7 +37,7 @@ func (c *Commands) cmdRename(args []string, bang bool, eval *cmdRenameEval) {
 func (c *Commands) Rename(args []string, bang bool, eval *cmdRenameEval) error {
 	defer nvimutil.Profile(time.Now(), "GoRename")
 	dir := filepath.Dir(eval.File)
-	defer c.ctx.SetContext(dir)()
+	bctxt := context.NewBuildContext(dir)
 
 	var (
 		b nvim.Buffer
@@ -96,7 +96,7 @@ func (c *Commands) Rename(args []string, bang bool, eval *cmdRenameEval) error {
 		os.Stderr = saveStderr
 	}()
 
-	if err := rename.Main(&build.Default, pos, "", renameTo); err != nil {
+	if err := rename.Main(&bctxt.Context, pos, "", renameTo); err != nil {
 		write.Close()
 		renameErr, err := ioutil.ReadAll(read)
 		if err != nil {
@@ -105,7 +105,7 @@ func (c *Commands) Rename(args []string, bang bool, eval *cmdRenameEval) error {
 
 		log.Printf("er: %+v\n", string(renameErr))
 		go func() {
-			loclist, _ := nvimutil.ParseError(renameErr, eval.Cwd, &c.ctx.Build)
+			loclist, _ := nvimutil.ParseError(renameErr, eval.Cwd, bctxt)
 			nvimutil.SetLoclist(c.Nvim, loclist)
 			nvimutil.OpenLoclist(c.Nvim, w, loclist, true)
 		}()
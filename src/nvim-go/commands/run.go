@@ -9,8 +9,8 @@ import (
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
 	"nvim-go/nvimutil"
-	"nvim-go/pathutil"
 
 	"github.com/pkg/errors"
 )
@@ -51,8 +51,10 @@ func (c *Commands) Run(cmd []string, file string) error {
 		runTerm = nvimutil.NewTerminal(c.Nvim, "__GO_RUN__", cmd, config.TerminalMode)
 	}
 	dir, _ := filepath.Split(file)
-	rootDir := pathutil.FindVCSRoot(dir)
-	runTerm.Dir = rootDir
+	runTerm.Dir = dir
+	if root := context.NewBuildContext(dir).Build.VCSRoot; root != "" {
+		runTerm.Dir = root
+	}
 
 	if err := runTerm.Run(cmd); err != nil {
 		return errors.WithStack(err)
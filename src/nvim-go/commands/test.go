@@ -7,7 +7,6 @@ package commands
 import (
 	"fmt"
 	"go/ast"
-	"go/build"
 	"go/parser"
 	"go/token"
 	"log"
@@ -17,6 +16,8 @@ import (
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
+	"nvim-go/internal/gotest"
 	"nvim-go/nvimutil"
 	"nvim-go/pathutil"
 
@@ -39,48 +40,150 @@ var testTerm *nvimutil.Terminal
 // the directory structure.
 func (c *Commands) Test(args []string, dir string) error {
 	defer nvimutil.Profile(time.Now(), "GoTest")
-	defer c.ctx.SetContext(dir)()
+	bctxt := context.NewBuildContext(dir)
 
-	cmd := []string{c.ctx.Build.Tool, "test", strings.Join(config.TestFlags, " ")}
-	if len(args) > 0 {
-		cmd = append(cmd, args...)
+	testPkgs, err := testPackages(bctxt, dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return c.runTest(bctxt, dir, args, testPkgs)
+}
+
+// runTest runs the test of pkgs with args on the test terminal, or parses
+// the "go test -json" output if config.TestMode is "json".
+// The dir is the directory of bctxt, which used for re-run from the test history.
+func (c *Commands) runTest(bctxt *context.BuildContext, dir string, args, pkgs []string) error {
+	testDir := testWorkDir(bctxt, dir)
+
+	// gb test does not support the -json flag
+	if config.TestMode == "json" && bctxt.Build.Tool != "gb" {
+		result, err := c.testJSON(bctxt, testDir, args, pkgs)
+		c.saveTestHistory(bctxt, dir, args, pkgs, result)
+		return err
+	}
+
+	cmd := []string{bctxt.Build.Command(), "test", strings.Join(config.TestFlags, " ")}
+	cmd = append(cmd, args...)
+	cmd = append(cmd, pkgs...)
+	log.Println(cmd)
+
+	if testTerm == nil {
+		testTerm = nvimutil.NewTerminal(c.Nvim, "__GO_TEST__", cmd, config.TerminalMode)
+	}
+	testTerm.Dir = testDir
+
+	if err := testTerm.Run(cmd); err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+	// The result of the terminal is unknown
+	c.saveTestHistory(bctxt, dir, args, pkgs, "")
+
+	return nil
+}
+
+// testWorkDir returns the working directory of the test command for the dir
+// package.
+func testWorkDir(bctxt *context.BuildContext, dir string) string {
+	// The package ID of module is resolved only inside of the module
+	if bctxt.Build.Tool == "mod" {
+		return bctxt.Build.ProjectRoot
 	}
+	if bctxt.Build.VCSRoot != "" {
+		return bctxt.Build.VCSRoot
+	}
+	return dir
+}
 
+// testPackages returns the package IDs of the test target, which is the dir
+// package or the all packages under the dir if config.TestAll is true.
+func testPackages(bctxt *context.BuildContext, dir string) ([]string, error) {
 	var testPkgs []string
 	if config.TestAll {
-		switch c.ctx.Build.Tool {
+		switch bctxt.Build.Tool {
 		case "go":
-			pkgs, err := pathutil.FindAllPackage(dir, build.Default, nil, pathutil.ModeExcludeVendor)
+			pkgs, err := pathutil.FindAllPackage(dir, bctxt.Context, nil, pathutil.ModeExcludeVendor)
 			if err != nil {
-				return errors.WithStack(err)
+				return nil, errors.WithStack(err)
 			}
 			for _, p := range pkgs {
-				testPkgs = append(testPkgs, pathutil.TrimGoPath(p.Dir))
+				testPkgs = append(testPkgs, pathutil.TrimGoPath(&bctxt.Context, p.Dir))
+			}
+		case "mod":
+			pkgs, err := pathutil.FindAllPackage(dir, bctxt.Context, nil, pathutil.ModeExcludeVendor)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			for _, p := range pkgs {
+				pkgID, err := pathutil.ModulePackageID(bctxt.Build.ProjectRoot, bctxt.Build.ModulePath, p.Dir)
+				if err != nil {
+					return nil, errors.WithStack(err)
+				}
+				testPkgs = append(testPkgs, pkgID)
 			}
 		case "gb":
 			// nothing to do
 		}
 	} else {
-		pkgs, err := pathutil.PackageID(dir)
+		pkgs, err := pathutil.PackageIDContext(&bctxt.Context, dir)
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, errors.WithStack(err)
 		}
 		testPkgs = append(testPkgs, pkgs)
 	}
 
-	cmd = append(cmd, testPkgs...)
-	log.Println(cmd)
+	return testPkgs, nil
+}
 
-	if testTerm == nil {
-		testTerm = nvimutil.NewTerminal(c.Nvim, "__GO_TEST__", cmd, config.TerminalMode)
-		testTerm.Dir = pathutil.FindVCSRoot(dir)
+// ----------------------------------------------------------------------------
+// GoTestFunc
+
+type cmdTestFuncEval struct {
+	Cwd    string `msgpack:",array"`
+	File   string
+	Offset int
+}
+
+func (c *Commands) cmdTestFunc(args []string, eval *cmdTestFuncEval) {
+	go c.TestFunc(args, eval)
+}
+
+// TestFunc runs the test, benchmark or example function of the current cursor.
+// If the cursor is inside of the t.Run subtest which name is string literal,
+// runs only that subtest.
+func (c *Commands) TestFunc(args []string, eval *cmdTestFuncEval) error {
+	defer nvimutil.Profile(time.Now(), "GoTestFunc")
+
+	b, err := c.Nvim.CurrentBuffer()
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+	buf, err := c.Nvim.BufferLines(b, 0, -1, true)
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
 	}
 
-	if err := testTerm.Run(cmd); err != nil {
+	fset := token.NewFileSet()
+	f := parse(eval.File, fset, nvimutil.ToByteSlice(buf))
+	if f == nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.New("couldn't parse of the current buffer"))
+	}
+	offset := fset.File(f.Pos()).Pos(eval.Offset)
+	path, _ := astutil.PathEnclosingInterval(f, offset, offset)
+
+	fn, err := gotest.Enclosing(path)
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, err)
+	}
+
+	dir := filepath.Dir(eval.File)
+	bctxt := context.NewBuildContext(dir)
+	pkgID, err := pathutil.PackageIDContext(&bctxt.Context, dir)
+	if err != nil {
 		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
 	}
 
-	return nil
+	return c.runTest(bctxt, dir, append(fn.Flags(""), args...), []string{pkgID})
 }
 
 // ----------------------------------------------------------------------------
@@ -132,9 +235,6 @@ func (c *Commands) SwitchTest(eval *cmdTestSwitchEval) error {
 		return errors.New("Does not exist the switching destination file")
 	}
 
-	// Get the current buffer package context
-	defer c.ctx.SetContext(filepath.Dir(fname))()
-
 	var (
 		b nvim.Buffer
 		w nvim.Window
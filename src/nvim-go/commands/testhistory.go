@@ -0,0 +1,287 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/context"
+	"nvim-go/internal/storage"
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	testHistoryName = "__GO_TEST_HISTORY__"
+	// testHistoryMax maximum number of the saved test history entries per project.
+	testHistoryMax = 100
+	// testHistoryDirVar buffer variable name of the test history buffer which
+	// stores the package directory of the listed history.
+	testHistoryDirVar = "go_test_history_dir"
+	// testHistoryIDsVar buffer variable name of the test history buffer which
+	// stores the entry IDs of the listed history by line.
+	testHistoryIDsVar = "go_test_history_ids"
+)
+
+var (
+	// testHistoryMu Mutex lock for the test history files and testHistoryBuf.
+	testHistoryMu sync.Mutex
+	// testHistoryBuf test history buffer of the GoTestHistory.
+	testHistoryBuf *nvimutil.Buffer
+)
+
+// testHistoryEntry represents a test invocation of the test history.
+type testHistoryEntry struct {
+	// Command command line of the test, for display.
+	Command []string `json:"command"`
+	// Dir package directory of the test, which used for re-run.
+	Dir string `json:"dir"`
+	// Packages test target packages.
+	Packages []string `json:"packages"`
+	// Run -run flag pattern.
+	Run string `json:"run,omitempty"`
+	// Flags test flags without the config.TestFlags.
+	Flags []string `json:"flags"`
+	// Result "pass" or "fail", or empty if the result is unknown such as run on the terminal.
+	Result string `json:"result,omitempty"`
+	// Time time of the test invocation.
+	Time time.Time `json:"time"`
+}
+
+// ID returns the stable ID of the entry, which is not changed by the later
+// test invocations unlike the index of the history.
+func (e *testHistoryEntry) ID() string {
+	return strconv.FormatInt(e.Time.UnixNano(), 10)
+}
+
+// findTestHistory returns the entry of the id in history, or nil if not found.
+func findTestHistory(history []*testHistoryEntry, id string) *testHistoryEntry {
+	for _, e := range history {
+		if e.ID() == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// testHistoryFile returns the test history file path of the project.
+func testHistoryFile(project string) string {
+	return storage.DataPath("test-history", project, "history.json")
+}
+
+// loadTestHistory loads the test history of fname ordered by oldest first.
+// Returns empty history if fname does not exist.
+func loadTestHistory(fname string) ([]*testHistoryEntry, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var history []*testHistoryEntry
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, errors.Wrapf(err, "invalid test history file: %s", fname)
+	}
+
+	return history, nil
+}
+
+// appendTestHistory appends entry to the test history of fname, and drops the
+// oldest entries over the testHistoryMax.
+func appendTestHistory(fname string, entry *testHistoryEntry) error {
+	history, err := loadTestHistory(fname)
+	if err != nil {
+		return err
+	}
+	history = append(history, entry)
+	if len(history) > testHistoryMax {
+		history = history[len(history)-testHistoryMax:]
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(ioutil.WriteFile(fname, data, 0644))
+}
+
+// testRunFlag returns the -run flag pattern of args.
+func testRunFlag(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "-run" || arg == "--run":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "-run="), strings.HasPrefix(arg, "--run="):
+			return arg[strings.Index(arg, "=")+1:]
+		}
+	}
+	return ""
+}
+
+// saveTestHistory saves the test invocation to the project test history.
+// The failure of save is only logged, because it is not a failure of the test.
+func (c *Commands) saveTestHistory(bctxt *context.BuildContext, dir string, args, pkgs []string, result string) {
+	cmd := []string{bctxt.Build.Command(), "test"}
+	cmd = append(cmd, args...)
+	cmd = append(cmd, pkgs...)
+
+	entry := &testHistoryEntry{
+		Command:  cmd,
+		Dir:      dir,
+		Packages: pkgs,
+		Run:      testRunFlag(args),
+		Flags:    args,
+		Result:   result,
+		Time:     time.Now(),
+	}
+
+	testHistoryMu.Lock()
+	defer testHistoryMu.Unlock()
+
+	if err := appendTestHistory(testHistoryFile(storage.ProjectRoot(bctxt, dir)), entry); err != nil {
+		log.Printf("couldn't save the test history: %+v", err)
+	}
+}
+
+// projectTestHistory returns the test history of dir's project ordered by newest first.
+func projectTestHistory(dir string) ([]*testHistoryEntry, error) {
+	bctxt := context.NewBuildContext(dir)
+
+	testHistoryMu.Lock()
+	history, err := loadTestHistory(testHistoryFile(storage.ProjectRoot(bctxt, dir)))
+	testHistoryMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+
+	return history, nil
+}
+
+// rerunTest re-runs the test of the test history entry.
+func (c *Commands) rerunTest(entry *testHistoryEntry) error {
+	bctxt := context.NewBuildContext(entry.Dir)
+
+	return c.runTest(bctxt, entry.Dir, entry.Flags, entry.Packages)
+}
+
+// ----------------------------------------------------------------------------
+// GoTestLast
+
+func (c *Commands) cmdTestLast(dir string) {
+	go c.TestLast(dir)
+}
+
+// TestLast re-runs the last test of the current buffer's project.
+func (c *Commands) TestLast(dir string) error {
+	defer nvimutil.Profile(time.Now(), "GoTestLast")
+
+	history, err := projectTestHistory(dir)
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, err)
+	}
+	if len(history) == 0 {
+		return nvimutil.ErrorWrap(c.Nvim, errors.New("not found the test history"))
+	}
+
+	return c.rerunTest(history[0])
+}
+
+// ----------------------------------------------------------------------------
+// GoTestHistory
+
+func (c *Commands) cmdTestHistory(args []string, dir string) {
+	go c.TestHistory(args, dir)
+}
+
+// TestHistory lists the test history of the current buffer's project to the
+// test history buffer ordered by newest first.
+// If args is the ID of the history entry, re-runs that test instead of list.
+func (c *Commands) TestHistory(args []string, dir string) error {
+	defer nvimutil.Profile(time.Now(), "GoTestHistory")
+
+	history, err := projectTestHistory(dir)
+	if err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, err)
+	}
+	if len(history) == 0 {
+		return nvimutil.ErrorWrap(c.Nvim, errors.New("not found the test history"))
+	}
+
+	if len(args) > 0 {
+		entry := findTestHistory(history, args[0])
+		if entry == nil {
+			return nvimutil.ErrorWrap(c.Nvim, errors.Errorf("not found the test history: %s", args[0]))
+		}
+		return c.rerunTest(entry)
+	}
+
+	return nvimutil.ErrorWrap(c.Nvim, c.renderTestHistory(history, dir))
+}
+
+// renderTestHistory renders the history to the test history buffer.
+// The entry IDs are stored to the buffer variable by line, because the index
+// of the history is shifted by the later test invocations.
+func (c *Commands) renderTestHistory(history []*testHistoryEntry, dir string) error {
+	testHistoryMu.Lock()
+	defer testHistoryMu.Unlock()
+
+	buf, err := c.openScratch(testHistoryBuf, testHistoryName, nvimutil.FiletypeGoTest, "silent belowright 10 split", scratchOption(nvimutil.FiletypeGoTest))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if buf != testHistoryBuf {
+		// <CR> re-runs the test of the cursor line entry
+		if err := buf.SetLocalMapping("nnoremap", map[string]string{
+			"<CR>": fmt.Sprintf(":<C-u>execute 'GoTestHistory' b:%s[line('.') - 1]<CR>", testHistoryIDsVar),
+		}); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	testHistoryBuf = buf
+
+	// GoTestHistory in the test history buffer uses the dir instead of buffer name
+	if err := c.Nvim.SetBufferVar(testHistoryBuf.Buffer(), testHistoryDirVar, dir); err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines := make([][]byte, len(history))
+	ids := make([]string, len(history))
+	for i, e := range history {
+		ids[i] = e.ID()
+		result := "-"
+		if e.Result != "" {
+			result = strings.ToUpper(e.Result)
+		}
+		lines[i] = []byte(fmt.Sprintf("%-4s %s  %s", result, e.Time.Format("2006-01-02 15:04:05"), strings.Join(e.Command, " ")))
+	}
+
+	if err := c.Nvim.SetBufferVar(testHistoryBuf.Buffer(), testHistoryIDsVar, ids); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return replaceLines(c.Nvim, testHistoryBuf.Buffer(), lines)
+}
@@ -0,0 +1,135 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTestRunFlag(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "separated",
+			args: []string{"-v", "-run", "^TestFoo$"},
+			want: "^TestFoo$",
+		},
+		{
+			name: "equal",
+			args: []string{"-run=^TestFoo$/^sub$", "-v"},
+			want: "^TestFoo$/^sub$",
+		},
+		{
+			name: "double dash",
+			args: []string{"--run", "Foo"},
+			want: "Foo",
+		},
+		{
+			name: "no run flag",
+			args: []string{"-v"},
+			want: "",
+		},
+		{
+			name: "missing value",
+			args: []string{"-run"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testRunFlag(tt.args); got != tt.want {
+				t.Errorf("testRunFlag(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendTestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-test-history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "history", "project.json")
+
+	history, err := loadTestHistory(fname)
+	if err != nil || len(history) != 0 {
+		t.Fatalf("loadTestHistory(%v) = %v, %v, want empty history", fname, history, err)
+	}
+
+	for i := 0; i < testHistoryMax+2; i++ {
+		if err := appendTestHistory(fname, &testHistoryEntry{Packages: []string{strconv.Itoa(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err = loadTestHistory(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != testHistoryMax {
+		t.Fatalf("len(history) = %d, want %d", len(history), testHistoryMax)
+	}
+	if got, want := history[0].Packages[0], "2"; got != want {
+		t.Errorf("oldest entry = %v, want %v", got, want)
+	}
+	if got, want := history[len(history)-1].Packages[0], strconv.Itoa(testHistoryMax+1); got != want {
+		t.Errorf("newest entry = %v, want %v", got, want)
+	}
+}
+
+func TestFindTestHistory(t *testing.T) {
+	base := time.Date(2017, 1, 2, 3, 4, 5, 6, time.UTC)
+	history := []*testHistoryEntry{
+		{Packages: []string{"foo"}, Time: base},
+		{Packages: []string{"bar"}, Time: base.Add(time.Second)},
+	}
+	id := history[1].ID()
+	// the later test invocation shifts the index, but does not change the id
+	history = append([]*testHistoryEntry{{Packages: []string{"baz"}, Time: base.Add(2 * time.Second)}}, history...)
+
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{
+			name: "found",
+			id:   id,
+			want: "bar",
+		},
+		{
+			name: "not found",
+			id:   "1",
+			want: "",
+		},
+		{
+			name: "index",
+			id:   "2",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if e := findTestHistory(history, tt.id); e != nil {
+				got = e.Packages[0]
+			}
+			if got != tt.want {
+				t.Errorf("findTestHistory(%v) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
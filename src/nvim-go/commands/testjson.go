@@ -0,0 +1,343 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/context"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const testTreeName = "__GO_TEST_TREE__"
+
+// testTree test tree buffer of the GoTest json mode.
+var testTree *nvimutil.Buffer
+
+// testEvent represents a JSON event of the "go test -json" output.
+// The fields are same as the cmd/test2json TestEvent.
+type testEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// testNode represents a package, test or subtest node of the test tree.
+type testNode struct {
+	// Name name of the package, test or the last element of subtest.
+	Name string
+	// Test full test name, empty if the node is package.
+	Test string
+	// Action result of the node. "pass", "fail", "skip" or empty if not finished.
+	Action  string
+	Elapsed float64
+	Output  []string
+
+	Children []*testNode
+}
+
+// Status returns the upper case status of n.
+func (n *testNode) Status() string {
+	if n.Action == "" {
+		return "RUN"
+	}
+	return strings.ToUpper(n.Action)
+}
+
+// testReport represents a parsed "go test -json" output.
+type testReport struct {
+	Packages []*testNode
+	// Output non-JSON output lines, e.g. the build failure.
+	Output []string
+
+	nodes map[string]*testNode
+}
+
+var (
+	// testFrameRe matches the framing lines of go test output which represented by the tree.
+	testFrameRe = regexp.MustCompile(`^(=== (RUN|PAUSE|CONT|NAME) |\s*--- (PASS|FAIL|SKIP): |(PASS|FAIL)$|ok  \t|FAIL\t|\?   \t)`)
+	// testLocationRe matches the failure location such as "foo_test.go:12: message".
+	testLocationRe = regexp.MustCompile(`^\s*([^\s:]+\.go):(\d+)(?::(\d+))?: (.*)$`)
+)
+
+// parseTestJSON parses the "go test -json" output to the testReport.
+func parseTestJSON(r io.Reader) (*testReport, error) {
+	report := &testReport{nodes: make(map[string]*testNode)}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			report.Output = append(report.Output, string(line))
+			continue
+		}
+		var ev testEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		report.add(&ev)
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return report, nil
+}
+
+// add adds ev to the node of report.
+func (r *testReport) add(ev *testEvent) {
+	n := r.node(ev.Package, ev.Test)
+
+	switch ev.Action {
+	case "pass", "fail", "skip":
+		n.Action = ev.Action
+		n.Elapsed = ev.Elapsed
+	case "output":
+		out := strings.TrimRight(ev.Output, "\n")
+		if testFrameRe.MatchString(out) {
+			return
+		}
+		n.Output = append(n.Output, out)
+	}
+}
+
+// node returns the test node of the pkg package, creates the node and parents if not exist.
+func (r *testReport) node(pkg, test string) *testNode {
+	key := pkg + "\x00" + test
+	if n, ok := r.nodes[key]; ok {
+		return n
+	}
+
+	if test == "" {
+		n := &testNode{Name: pkg}
+		r.nodes[key] = n
+		r.Packages = append(r.Packages, n)
+		return n
+	}
+
+	parent, name := "", test
+	if i := strings.LastIndex(test, "/"); i >= 0 {
+		parent, name = test[:i], test[i+1:]
+	}
+	p := r.node(pkg, parent)
+	n := &testNode{Name: name, Test: test}
+	p.Children = append(p.Children, n)
+	r.nodes[key] = n
+
+	return n
+}
+
+// Failed reports whether the any package was failed.
+func (r *testReport) Failed() bool {
+	for _, p := range r.Packages {
+		if p.Action == "fail" {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of pass, fail and skip of tests, not contains the packages.
+func (r *testReport) Count() (pass, fail, skip int) {
+	var walk func(nodes []*testNode)
+	walk = func(nodes []*testNode) {
+		for _, n := range nodes {
+			switch n.Action {
+			case "pass":
+				pass++
+			case "fail":
+				fail++
+			case "skip":
+				skip++
+			}
+			walk(n.Children)
+		}
+	}
+	for _, p := range r.Packages {
+		walk(p.Children)
+	}
+
+	return pass, fail, skip
+}
+
+// Errlist returns the failure locations of the failed tests.
+// pkgDir returns the directory of the package import path, which used for
+// resolve the file name of the location.
+func (r *testReport) Errlist(pkgDir func(pkg string) string) []*nvim.QuickfixError {
+	var errlist []*nvim.QuickfixError
+
+	var walk func(dir string, n *testNode)
+	walk = func(dir string, n *testNode) {
+		if n.Action == "fail" {
+			for _, out := range n.Output {
+				m := testLocationRe.FindStringSubmatch(out)
+				if m == nil {
+					continue
+				}
+				fname := m[1]
+				if !filepath.IsAbs(fname) {
+					fname = filepath.Join(dir, fname)
+				}
+				lnum, _ := strconv.Atoi(m[2])
+				col, _ := strconv.Atoi(m[3])
+				text := m[4]
+				if n.Test != "" {
+					text = n.Test + ": " + text
+				}
+				errlist = append(errlist, &nvim.QuickfixError{
+					FileName: fname,
+					LNum:     lnum,
+					Col:      col,
+					Text:     text,
+				})
+			}
+		}
+		for _, child := range n.Children {
+			walk(dir, child)
+		}
+	}
+	for _, p := range r.Packages {
+		walk(pkgDir(p.Name), p)
+	}
+
+	return errlist
+}
+
+// Lines returns the indented test tree lines for the foldmethod=indent.
+func (r *testReport) Lines() [][]byte {
+	var buf bytes.Buffer
+
+	var render func(n *testNode, depth int)
+	render = func(n *testNode, depth int) {
+		indent := strings.Repeat("  ", depth)
+		fmt.Fprintf(&buf, "%s%s %s (%.2fs)\n", indent, n.Status(), n.Name, n.Elapsed)
+		for _, out := range n.Output {
+			fmt.Fprintf(&buf, "%s  %s\n", indent, strings.TrimSpace(out))
+		}
+		for _, child := range n.Children {
+			render(child, depth+1)
+		}
+	}
+	for _, p := range r.Packages {
+		render(p, 0)
+	}
+	for _, out := range r.Output {
+		fmt.Fprintln(&buf, out)
+	}
+
+	return nvimutil.ToBufferLines(bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}))
+}
+
+// testJSON runs the "go test -json" on dir, and sets the failure locations to
+// the Errlist and renders the test tree buffer.
+// The returned result is "pass" or "fail", or empty if could not run the test.
+func (c *Commands) testJSON(bctxt *context.BuildContext, dir string, args, pkgs []string) (string, error) {
+	cmd := exec.Command(bctxt.Build.Command(), "test", "-json")
+	cmd.Args = append(cmd.Args, config.TestFlags...)
+	cmd.Args = append(cmd.Args, args...)
+	cmd.Args = append(cmd.Args, pkgs...)
+	cmd.Dir = dir
+	cmd.Env = bctxt.Env
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	testErr := cmd.Run()
+	if testErr != nil {
+		if _, ok := testErr.(*exec.ExitError); !ok {
+			return "", nvimutil.ErrorWrap(c.Nvim, errors.WithStack(testErr))
+		}
+	}
+
+	report, err := parseTestJSON(&stdout)
+	if err != nil {
+		return "", nvimutil.ErrorWrap(c.Nvim, err)
+	}
+
+	errlist := report.Errlist(func(pkg string) string {
+		return testPackageDir(bctxt, pkg, dir)
+	})
+	if testErr != nil && len(errlist) == 0 && stderr.Len() > 0 {
+		// the build failure of the test is written to stderr as a plain text
+		errlist, err = nvimutil.ParseError(stderr.Bytes(), dir, bctxt)
+		if err != nil {
+			return "", nvimutil.ErrorWrap(c.Nvim, err)
+		}
+		report.Output = append(report.Output, strings.Split(strings.TrimSpace(stderr.String()), "\n")...)
+	}
+
+	if err := c.renderTestTree(report); err != nil {
+		return "", nvimutil.ErrorWrap(c.Nvim, err)
+	}
+
+	pass, fail, skip := report.Count()
+	msg := fmt.Sprintf("PASS: %d FAIL: %d SKIP: %d", pass, fail, skip)
+	if len(errlist) > 0 {
+		c.ctx.SetErrlist("Test", errlist)
+		nvimutil.ErrorList(c.Nvim, c.ctx.CopyErrlist(), true)
+		return "fail", nvimutil.EchohlErr(c.Nvim, "GoTest", msg)
+	}
+	c.ctx.DeleteErrlist("Test")
+	if testErr != nil || report.Failed() {
+		return "fail", nvimutil.EchohlErr(c.Nvim, "GoTest", msg)
+	}
+	nvimutil.ClearErrorlist(c.Nvim, true)
+
+	return "pass", nvimutil.EchoSuccess(c.Nvim, "GoTest", msg)
+}
+
+// renderTestTree renders the report to the test tree buffer.
+func (c *Commands) renderTestTree(report *testReport) error {
+	option := scratchOption(nvimutil.FiletypeGoTest)
+	option[nvimutil.BufferOption][nvimutil.BufOptionShiftwidth] = 2
+	option[nvimutil.WindowOption][nvimutil.WinOptionFoldmethod] = "indent"
+	option[nvimutil.WindowOption][nvimutil.WinOptionFoldlevel] = 99
+
+	buf, err := c.openScratch(testTree, testTreeName, nvimutil.FiletypeGoTest, fmt.Sprintf("silent %s %s", config.TerminalPosition, config.TerminalMode), option)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	testTree = buf
+
+	return replaceLines(c.Nvim, testTree.Buffer(), report.Lines())
+}
+
+// testPackageDir returns the directory of the pkg import path.
+// Fallbacks to the dir if could not find the package.
+func testPackageDir(bctxt *context.BuildContext, pkg, dir string) string {
+	if bctxt.Build.Tool == "mod" {
+		switch {
+		case pkg == bctxt.Build.ModulePath:
+			return bctxt.Build.ProjectRoot
+		case strings.HasPrefix(pkg, bctxt.Build.ModulePath+"/"):
+			return filepath.Join(bctxt.Build.ProjectRoot, filepath.FromSlash(strings.TrimPrefix(pkg, bctxt.Build.ModulePath+"/")))
+		}
+	}
+	if p, err := bctxt.Context.Import(pkg, dir, build.FindOnly); err == nil {
+		return p.Dir
+	}
+
+	return dir
+}
@@ -0,0 +1,69 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+const testJSONOutput = `{"Action":"run","Package":"foo.org/bar","Test":"TestA"}
+{"Action":"output","Package":"foo.org/bar","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"output","Package":"foo.org/bar","Test":"TestA","Output":"--- PASS: TestA (0.00s)\n"}
+{"Action":"pass","Package":"foo.org/bar","Test":"TestA","Elapsed":0}
+{"Action":"run","Package":"foo.org/bar","Test":"TestB"}
+{"Action":"run","Package":"foo.org/bar","Test":"TestB/sub"}
+{"Action":"output","Package":"foo.org/bar","Test":"TestB/sub","Output":"    --- FAIL: TestB/sub (0.01s)\n"}
+{"Action":"output","Package":"foo.org/bar","Test":"TestB/sub","Output":"    \tbar_test.go:12: got 1, want 2\n"}
+{"Action":"fail","Package":"foo.org/bar","Test":"TestB/sub","Elapsed":0.01}
+{"Action":"run","Package":"foo.org/bar","Test":"TestB/skip"}
+{"Action":"skip","Package":"foo.org/bar","Test":"TestB/skip","Elapsed":0}
+{"Action":"output","Package":"foo.org/bar","Test":"TestB","Output":"--- FAIL: TestB (0.01s)\n"}
+{"Action":"fail","Package":"foo.org/bar","Test":"TestB","Elapsed":0.01}
+{"Action":"output","Package":"foo.org/bar","Output":"FAIL\n"}
+{"Action":"output","Package":"foo.org/bar","Output":"FAIL\tfoo.org/bar\t0.02s\n"}
+{"Action":"fail","Package":"foo.org/bar","Elapsed":0.02}
+`
+
+func TestParseTestJSON(t *testing.T) {
+	report, err := parseTestJSON(strings.NewReader(testJSONOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantLines := []string{
+		"FAIL foo.org/bar (0.02s)",
+		"  PASS TestA (0.00s)",
+		"  FAIL TestB (0.01s)",
+		"    FAIL sub (0.01s)",
+		"      bar_test.go:12: got 1, want 2",
+		"    SKIP skip (0.00s)",
+	}
+	var gotLines []string
+	for _, l := range report.Lines() {
+		gotLines = append(gotLines, string(l))
+	}
+	if !reflect.DeepEqual(gotLines, wantLines) {
+		t.Errorf("Lines() = %q, want %q", gotLines, wantLines)
+	}
+
+	if !report.Failed() {
+		t.Errorf("Failed() = false, want true")
+	}
+	if pass, fail, skip := report.Count(); pass != 1 || fail != 2 || skip != 1 {
+		t.Errorf("Count() = %d, %d, %d, want 1, 2, 1", pass, fail, skip)
+	}
+
+	wantErrlist := []*nvim.QuickfixError{
+		{FileName: "/go/src/foo.org/bar/bar_test.go", LNum: 12, Text: "TestB/sub: got 1, want 2"},
+	}
+	gotErrlist := report.Errlist(func(pkg string) string { return "/go/src/" + pkg })
+	if !reflect.DeepEqual(gotErrlist, wantErrlist) {
+		t.Errorf("Errlist() = %v, want %v", gotErrlist, wantErrlist)
+	}
+}
@@ -7,6 +7,7 @@ package commands
 import (
 	"fmt"
 
+	"nvim-go/context"
 	"nvim-go/nvimutil"
 )
 
@@ -38,6 +39,11 @@ func (c *Commands) cmdTabpagas() error {
 	return nvimutil.Echomsg(c.Nvim, "Tabpages:", t)
 }
 
+func (c *Commands) cmdBuildCache() error {
+	stats := context.BuildCacheStats()
+	return nvimutil.Echomsg(c.Nvim, "BuildCache:", fmt.Sprintf("entries=%d hits=%d misses=%d invalidations=%d", stats.Entries, stats.Hits, stats.Misses, stats.Invalidations))
+}
+
 func (c *Commands) cmdByteOffset() error {
 	b, err := c.Nvim.CurrentBuffer()
 	if err != nil {
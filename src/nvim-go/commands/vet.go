@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"nvim-go/config"
+	"nvim-go/context"
 	"nvim-go/nvimutil"
 	"nvim-go/pathutil"
 
@@ -28,7 +29,7 @@ type CmdVetEval struct {
 func (c *Commands) cmdVet(args []string, eval *CmdVetEval) {
 	go func() {
 		// Cleanup old results
-		c.ctx.Errlist["Vet"] = nil
+		c.ctx.SetErrlist("Vet", nil)
 
 		errlist, err := c.Vet(args, eval)
 		if err != nil {
@@ -36,13 +37,13 @@ func (c *Commands) cmdVet(args []string, eval *CmdVetEval) {
 			return
 		}
 		if errlist != nil {
-			c.ctx.Errlist["Vet"] = errlist
-			if len(c.ctx.Errlist) > 0 {
-				nvimutil.ErrorList(c.Nvim, c.ctx.Errlist, true)
+			c.ctx.SetErrlist("Vet", errlist)
+			if errs := c.ctx.CopyErrlist(); len(errs) > 0 {
+				nvimutil.ErrorList(c.Nvim, errs, true)
 				return
 			}
 		}
-		if c.ctx.Errlist["Vet"] == nil {
+		if c.ctx.CopyErrlist()["Vet"] == nil {
 			nvimutil.ClearErrorlist(c.Nvim, true)
 		}
 	}()
@@ -51,9 +52,11 @@ func (c *Commands) cmdVet(args []string, eval *CmdVetEval) {
 // Vet is a simple checker for static errors in Go source code use go tool vet command.
 func (c *Commands) Vet(args []string, eval *CmdVetEval) ([]*nvim.QuickfixError, error) {
 	defer nvimutil.Profile(time.Now(), "GoVet")
+	bctxt := context.NewBuildContext(filepath.Dir(eval.File))
 
 	vetCmd := exec.Command("go", "tool", "vet")
 	vetCmd.Dir = eval.Cwd
+	vetCmd.Env = bctxt.Env
 
 	switch {
 	case len(args) > 0:
@@ -86,7 +89,7 @@ func (c *Commands) Vet(args []string, eval *CmdVetEval) ([]*nvim.QuickfixError,
 
 	vetErr := vetCmd.Run()
 	if vetErr != nil {
-		errlist, err := nvimutil.ParseError(stderr.Bytes(), eval.Cwd, &c.ctx.Build)
+		errlist, err := nvimutil.ParseError(stderr.Bytes(), eval.Cwd, bctxt)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -34,7 +34,6 @@ func TestCommands_Vet(t *testing.T) {
 		args    args
 		want    []*nvim.QuickfixError
 		wantErr bool
-		tool    string
 	}{
 		// method.go:17: method Scan(x fmt.ScanState, c byte) should have signature Scan(fmt.ScanState, rune) error
 		// method.go:21: method ReadByte() byte should have signature ReadByte() (byte, error)
@@ -63,7 +62,6 @@ func TestCommands_Vet(t *testing.T) {
 				Text:     "method ReadByte() byte should have signature ReadByte() (byte, error)",
 			}},
 			wantErr: false,
-			tool:    "go",
 		},
 
 		// method.go:17: method Scan(x fmt.ScanState, c byte) should have signature Scan(fmt.ScanState, rune) error
@@ -129,11 +127,9 @@ func TestCommands_Vet(t *testing.T) {
 				Text:     "result of fmt.Sprintf call not used",
 			}},
 			wantErr: false,
-			tool:    "go",
 		},
 	}
 	for _, tt := range tests {
-		tt.fields.ctxt.Build.Tool = tt.tool
 		c := NewCommands(tt.fields.Nvim, tt.fields.ctxt)
 
 		tt := tt
@@ -0,0 +1,265 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/context"
+	"nvim-go/notify"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const watchLogName = "__GO_WATCH__"
+
+var (
+	// watchMu Mutex lock for watchers and watchLog.
+	watchMu sync.Mutex
+	// watchers running watchers keyed by package directory.
+	watchers = make(map[string]*notify.Watcher)
+	// watchLog log buffer of the GoWatch.
+	watchLog *nvimutil.Buffer
+)
+
+// ----------------------------------------------------------------------------
+// GoWatch
+
+type cmdWatchEval struct {
+	Cwd  string `msgpack:",array"`
+	File string
+}
+
+func (c *Commands) cmdWatch(eval *cmdWatchEval) {
+	go c.Watch(eval)
+}
+
+// Watch watches the current buffer's package directory, and runs the Build,
+// Vet and Test as configured when the Go package source files are changed.
+func (c *Commands) Watch(eval *cmdWatchEval) error {
+	defer nvimutil.Profile(time.Now(), "GoWatch")
+	dir := filepath.Dir(eval.File)
+
+	watchMu.Lock()
+	if _, ok := watchers[dir]; ok {
+		watchMu.Unlock()
+		return nvimutil.EchoSuccess(c.Nvim, "GoWatch", fmt.Sprintf("already watching %s", dir))
+	}
+	w, err := notify.NewWatcher(dir)
+	if err != nil {
+		watchMu.Unlock()
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+	watchers[dir] = w
+	watchMu.Unlock()
+
+	c.watchLogf("watching %s", dir)
+	go c.watchLoop(w, eval.Cwd)
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoWatch", fmt.Sprintf("watching %s", dir))
+}
+
+// watchLoop receives the events of w, and runs the tasks after the debounce time
+// was elapsed from the last event until the w is closed.
+func (c *Commands) watchLoop(w *notify.Watcher, cwd string) {
+	debounce := time.Duration(config.WatchDebounce) * time.Millisecond
+
+	var (
+		timer   <-chan time.Time
+		changed = make(map[string]bool)
+	)
+	for {
+		select {
+		case name, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			changed[name] = true
+			timer = time.After(debounce)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			c.watchLogf("error: %v", err)
+		case <-timer:
+			timer = nil
+			files := make([]string, 0, len(changed))
+			for name := range changed {
+				files = append(files, name)
+				delete(changed, name)
+			}
+			sort.Strings(files)
+			c.watchRun(w.Dir, cwd, files)
+		}
+	}
+}
+
+// watchRun runs the configured tasks for the dir package, and sets the results to the Errlist.
+func (c *Commands) watchRun(dir, cwd string, files []string) {
+	for _, f := range files {
+		c.watchLogf("changed: %s", pathutil.Rel(cwd, f))
+	}
+	// Build and Vet use the file name only for determine the package directory
+	file := files[0]
+
+	if config.WatchBuild {
+		bctxt := context.NewBuildContext(dir)
+		_, failed := c.ctx.CopyErrlist()["Build"]
+		switch e := c.build(bctxt, config.BuildForce, &CmdBuildEval{Cwd: cwd, File: file}).(type) {
+		case error:
+			c.watchLogf("build: %v", e)
+		case []*nvim.QuickfixError:
+			c.ctx.SetErrlist("Build", e)
+			c.watchLogErrors("build", e)
+		default:
+			c.ctx.DeleteErrlist("Build")
+			c.watchLogf("build: ok")
+			// echoes only when the build errors are fixed, not on every save
+			if failed {
+				nvimutil.EchoSuccess(c.Nvim, "GoBuild", fmt.Sprintf("compiler: %s", bctxt.Build.Tool))
+			}
+		}
+	}
+
+	if config.WatchVet {
+		errlist, err := c.Vet(nil, &CmdVetEval{Cwd: cwd, File: file})
+		switch {
+		case err != nil:
+			c.watchLogf("vet: %v", err)
+		case len(errlist) > 0:
+			c.ctx.SetErrlist("Vet", errlist)
+			c.watchLogErrors("vet", errlist)
+		default:
+			c.ctx.DeleteErrlist("Vet")
+			c.watchLogf("vet: ok")
+		}
+	}
+
+	if config.WatchTest {
+		errlist, err := c.watchTest(dir, cwd)
+		switch {
+		case err != nil:
+			c.watchLogf("test: %v", err)
+		case len(errlist) > 0:
+			c.ctx.SetErrlist("Test", errlist)
+			c.watchLogErrors("test", errlist)
+		default:
+			c.ctx.DeleteErrlist("Test")
+			c.watchLogf("test: ok")
+		}
+	}
+
+	if errlist := c.ctx.CopyErrlist(); len(errlist) > 0 {
+		nvimutil.ErrorList(c.Nvim, errlist, true)
+		return
+	}
+	nvimutil.ClearErrorlist(c.Nvim, true)
+}
+
+// watchTest runs the go test command for the dir package, and returns the parsed test failures.
+func (c *Commands) watchTest(dir, cwd string) ([]*nvim.QuickfixError, error) {
+	bctxt := context.NewBuildContext(dir)
+
+	cmd := exec.Command(bctxt.Build.Command(), "test")
+	cmd.Args = append(cmd.Args, config.TestFlags...)
+	cmd.Dir = dir
+	cmd.Env = bctxt.Env
+	if bctxt.Build.Tool == "gb" {
+		pkgID, err := pathutil.PackageIDContext(&bctxt.Context, dir)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		cmd.Args = append(cmd.Args, pkgID)
+		cmd.Dir = bctxt.Build.ProjectRoot
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if testErr := cmd.Run(); testErr != nil {
+		if _, ok := testErr.(*exec.ExitError); !ok {
+			return nil, errors.WithStack(testErr)
+		}
+		errlist, err := nvimutil.ParseError(out.Bytes(), cwd, bctxt)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(errlist) == 0 {
+			return nil, errors.New(string(bytes.TrimSpace(out.Bytes())))
+		}
+		return errlist, nil
+	}
+
+	return nil, nil
+}
+
+// watchLogErrors writes the errlist to the GoWatch log buffer.
+func (c *Commands) watchLogErrors(task string, errlist []*nvim.QuickfixError) {
+	c.watchLogf("%s: %d errors", task, len(errlist))
+	for _, e := range errlist {
+		c.watchLogf("  %s:%d:%d: %s", e.FileName, e.LNum, e.Col, e.Text)
+	}
+}
+
+// watchLogf writes the log message with timestamp to the GoWatch log buffer.
+func (c *Commands) watchLogf(format string, a ...interface{}) {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+
+	buf, err := c.openScratch(watchLog, watchLogName, nvimutil.FiletypeGoTerminal, "silent belowright 10 split", scratchOption(nvimutil.FiletypeGoTerminal))
+	if err != nil {
+		nvimutil.ErrorWrap(c.Nvim, err)
+		return
+	}
+	watchLog = buf
+
+	msg := fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), fmt.Sprintf(format, a...))
+	appendLines(c.Nvim, watchLog.Buffer(), nvimutil.ToBufferLines([]byte(msg)))
+}
+
+// ----------------------------------------------------------------------------
+// GoWatchStop
+
+func (c *Commands) cmdWatchStop(bang bool, eval *cmdWatchEval) {
+	go c.WatchStop(bang, eval)
+}
+
+// WatchStop stops the watching of the current buffer's package directory.
+// If bang is true, stops the all watchers.
+func (c *Commands) WatchStop(bang bool, eval *cmdWatchEval) error {
+	dir := filepath.Dir(eval.File)
+
+	watchMu.Lock()
+	var stopped []string
+	for d, w := range watchers {
+		if !bang && d != dir {
+			continue
+		}
+		w.Close()
+		delete(watchers, d)
+		stopped = append(stopped, d)
+	}
+	watchMu.Unlock()
+
+	if len(stopped) == 0 {
+		return nvimutil.ErrorWrap(c.Nvim, errors.Errorf("not watching %s", dir))
+	}
+	sort.Strings(stopped)
+	for _, d := range stopped {
+		c.watchLogf("stopped %s", d)
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoWatchStop", fmt.Sprintf("stopped %d watchers", len(stopped)))
+}
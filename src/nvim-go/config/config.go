@@ -14,6 +14,7 @@ type Config struct {
 
 	Analyze  analyze
 	Build    build
+	Delve    delve
 	Fmt      fmt
 	Generate generate
 	Guru     guru
@@ -22,6 +23,7 @@ type Config struct {
 	Rename   rename
 	Terminal terminal
 	Test     test
+	Watch    watch
 
 	Debug debug
 }
@@ -45,6 +47,11 @@ type build struct {
 	Flags    []string `eval:"g:go#build#flags"`
 }
 
+// delve represents a Dlv commands config variable.
+type delve struct {
+	Timeout int64 `eval:"g:go#delve#timeout"`
+}
+
 // fmt represents a GoFmt command config variable.
 type fmt struct {
 	Autosave int64  `eval:"g:go#fmt#autosave"`
@@ -104,6 +111,15 @@ type test struct {
 	AllPackage int64    `eval:"g:go#test#all_package"`
 	Autosave   int64    `eval:"g:go#test#autosave"`
 	Flags      []string `eval:"g:go#test#flags"`
+	Mode       string   `eval:"g:go#test#mode"`
+}
+
+// watch represents a GoWatch command config variables.
+type watch struct {
+	Build    int64 `eval:"g:go#watch#build"`
+	Vet      int64 `eval:"g:go#watch#vet"`
+	Test     int64 `eval:"g:go#watch#test"`
+	Debounce int64 `eval:"g:go#watch#debounce"`
 }
 
 // Debug represents a debug of nvim-go config variable.
@@ -130,6 +146,9 @@ var (
 	// BuildArgs force build args.
 	BuildFlags []string
 
+	// DelveTimeout timeout of waiting for the dlv server startup in milliseconds.
+	DelveTimeout int64
+
 	// FmtAutosave call the GoFmt command automatically at during the BufWritePre.
 	FmtAutosave bool
 	// FmtMode formatting mode of Fmt command.
@@ -192,6 +211,17 @@ var (
 	TestAll bool
 	// TestArgs test command default args.
 	TestFlags []string
+	// TestMode run the test on the terminal or parse the "go test -json" output. ("terminal" or "json")
+	TestMode string
+
+	// WatchBuild run the build on GoWatch when the source files are changed.
+	WatchBuild bool
+	// WatchVet run the vet on GoWatch when the source files are changed.
+	WatchVet bool
+	// WatchTest run the test on GoWatch when the source files are changed.
+	WatchTest bool
+	// WatchDebounce debounce time of GoWatch in milliseconds.
+	WatchDebounce int64
 
 	// DebugEnable Enable debugging.
 	DebugEnable bool
@@ -214,6 +244,9 @@ func Get(v *nvim.Nvim, cfg *Config) {
 	BuildForce = itob(cfg.Build.Force)
 	BuildFlags = cfg.Build.Flags
 
+	// Delve
+	DelveTimeout = cfg.Delve.Timeout
+
 	// Fmt
 	FmtAutosave = itob(cfg.Fmt.Autosave)
 	FmtMode = cfg.Fmt.Mode
@@ -258,6 +291,13 @@ func Get(v *nvim.Nvim, cfg *Config) {
 	TestAutosave = itob(cfg.Test.Autosave)
 	TestAll = itob(cfg.Test.AllPackage)
 	TestFlags = cfg.Test.Flags
+	TestMode = cfg.Test.Mode
+
+	// Watch
+	WatchBuild = itob(cfg.Watch.Build)
+	WatchVet = itob(cfg.Watch.Vet)
+	WatchTest = itob(cfg.Watch.Test)
+	WatchDebounce = cfg.Watch.Debounce
 
 	// Debug
 	DebugEnable = itob(cfg.Debug.Enable)
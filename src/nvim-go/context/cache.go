@@ -0,0 +1,159 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// layoutMarkers list of the file names which affects the estimated Build of
+// the descendant directories.
+var layoutMarkers = []string{"go.mod", "vendor", "src", ".git"}
+
+// cacheMax maximum number of the cached directories.
+const cacheMax = 256
+
+// stamp represents the state of the layout marker file at the time of caching.
+type stamp struct {
+	path    string
+	exists  bool
+	modTime time.Time
+}
+
+// newStamp returns the current state of the path.
+// The modification time is only recorded for regular files such as go.mod,
+// because the directory modification time changes on every file creation.
+func newStamp(path string) stamp {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return stamp{path: path}
+	}
+	s := stamp{path: path, exists: true}
+	if fi.Mode().IsRegular() {
+		s.modTime = fi.ModTime()
+	}
+	return s
+}
+
+// layoutStamps returns the stamps of the layout markers of dir and its all parents.
+func layoutStamps(dir string) []stamp {
+	var stamps []stamp
+	for {
+		for _, m := range layoutMarkers {
+			stamps = append(stamps, newStamp(filepath.Join(dir, m)))
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return stamps
+}
+
+// cacheEntry represents a cached Build and layout stamps.
+type cacheEntry struct {
+	build  Build
+	stamps []stamp
+}
+
+// valid reports whether the layout of the cached directory is unchanged.
+func (e *cacheEntry) valid() bool {
+	for _, s := range e.stamps {
+		if newStamp(s.path) != s {
+			return false
+		}
+	}
+	return true
+}
+
+// CacheStats represents a statistics of the build context cache.
+type CacheStats struct {
+	Entries       int
+	Hits          int
+	Misses        int
+	Invalidations int
+}
+
+// cacheKey represents a key of the cached Build, which includes the
+// environment affects the estimation in addition to the directory.
+type cacheKey struct {
+	dir         string
+	gopath      string
+	go111module string
+}
+
+// buildCache caches the Build estimated from the directory structure keyed by
+// directory and environment.
+type buildCache struct {
+	mu      sync.Mutex
+	entries map[cacheKey]*cacheEntry
+	// keys cached keys ordered by oldest first, for evict the oldest entry
+	// over the max.
+	keys  []cacheKey
+	max   int
+	stats CacheStats
+}
+
+// defaultCache default build context cache used by buildContext.
+var defaultCache = &buildCache{entries: make(map[cacheKey]*cacheEntry), max: cacheMax}
+
+// get returns the cached Build of key, and the estimate function result
+// if not cached yet or the directory layout was changed.
+func (c *buildCache) get(key cacheKey, estimate func(dir string) Build) Build {
+	key.dir = filepath.Clean(key.dir)
+	dir := key.dir
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && e.valid() {
+		c.mu.Lock()
+		c.stats.Hits++
+		c.mu.Unlock()
+		return e.build
+	}
+
+	// Take the stamps before estimation, so that a change during the
+	// estimation invalidates the entry on the next lookup.
+	stamps := layoutStamps(dir)
+	b := estimate(dir)
+
+	c.mu.Lock()
+	if ok {
+		c.stats.Invalidations++
+	}
+	c.stats.Misses++
+	if _, exists := c.entries[key]; !exists {
+		c.keys = append(c.keys, key)
+		for len(c.keys) > c.max {
+			delete(c.entries, c.keys[0])
+			c.keys = c.keys[1:]
+		}
+	}
+	c.entries[key] = &cacheEntry{build: b, stamps: stamps}
+	c.mu.Unlock()
+
+	return b
+}
+
+// Stats returns the statistics of c.
+func (c *buildCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
+
+// BuildCacheStats returns the statistics of the build context cache.
+func BuildCacheStats() CacheStats {
+	return defaultCache.Stats()
+}
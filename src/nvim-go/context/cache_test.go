@@ -0,0 +1,117 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildCache_get(t *testing.T) {
+	root, err := ioutil.TempDir("", "nvim-go-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "foo", "bar")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var estimated int
+	estimate := func(dir string) Build {
+		estimated++
+		return Build{Tool: "go", ProjectRoot: dir}
+	}
+
+	c := &buildCache{entries: make(map[cacheKey]*cacheEntry), max: cacheMax}
+	key := cacheKey{dir: dir}
+
+	tests := []struct {
+		name   string
+		change func() error
+		want   CacheStats
+	}{
+		{
+			name:   "first lookup",
+			change: func() error { return nil },
+			want:   CacheStats{Entries: 1, Misses: 1},
+		},
+		{
+			name:   "unchanged layout",
+			change: func() error { return nil },
+			want:   CacheStats{Entries: 1, Hits: 1, Misses: 1},
+		},
+		{
+			name: "create go.mod to the parent",
+			change: func() error {
+				return ioutil.WriteFile(filepath.Join(root, "foo", "go.mod"), []byte("module foo\n"), 0644)
+			},
+			want: CacheStats{Entries: 1, Hits: 1, Misses: 2, Invalidations: 1},
+		},
+		{
+			name:   "create vendor to the dir",
+			change: func() error { return os.Mkdir(filepath.Join(dir, "vendor"), 0755) },
+			want:   CacheStats{Entries: 1, Hits: 1, Misses: 3, Invalidations: 2},
+		},
+		{
+			name:   "create .git to the parent",
+			change: func() error { return os.Mkdir(filepath.Join(root, "foo", ".git"), 0755) },
+			want:   CacheStats{Entries: 1, Hits: 1, Misses: 4, Invalidations: 3},
+		},
+		{
+			name:   "unchanged layout after invalidation",
+			change: func() error { return nil },
+			want:   CacheStats{Entries: 1, Hits: 2, Misses: 4, Invalidations: 3},
+		},
+	}
+	for _, tt := range tests {
+		if err := tt.change(); err != nil {
+			t.Fatal(err)
+		}
+		c.get(key, estimate)
+		if got := c.Stats(); got != tt.want {
+			t.Errorf("%s: buildCache.Stats() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+	if estimated != 4 {
+		t.Errorf("estimated %d times, want 4", estimated)
+	}
+}
+
+func TestBuildCache_key(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	estimate := func(dir string) Build { return Build{Tool: "go", ProjectRoot: dir} }
+	c := &buildCache{entries: make(map[cacheKey]*cacheEntry), max: 2}
+
+	keys := []cacheKey{
+		{dir: dir, gopath: "/go"},
+		{dir: dir, gopath: "/go", go111module: "on"},
+		{dir: dir, gopath: "/gopath"},
+	}
+	for _, key := range keys {
+		c.get(key, estimate)
+	}
+	// the different environments are cached separately, and the oldest entry
+	// is evicted over the max
+	if got, want := c.Stats(), (CacheStats{Entries: 2, Misses: 3}); got != want {
+		t.Errorf("buildCache.Stats() = %+v, want %+v", got, want)
+	}
+	if _, ok := c.entries[keys[0]]; ok {
+		t.Errorf("the oldest entry %+v is not evicted", keys[0])
+	}
+
+	c.get(keys[2], estimate)
+	if got, want := c.Stats(), (CacheStats{Entries: 2, Hits: 1, Misses: 3}); got != want {
+		t.Errorf("buildCache.Stats() = %+v, want %+v", got, want)
+	}
+}
@@ -6,8 +6,10 @@ package context
 
 import (
 	"go/build"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"nvim-go/pathutil"
@@ -15,20 +17,70 @@ import (
 	"github.com/neovim/go-client/nvim"
 )
 
-// Context represents a embeded context package and build context.
+// Context represents a embeded context package and errorlist.
 type Context struct {
-	Build
-
+	// mu Mutex lock for the Errlist, which is accessed from the command,
+	// autocmd and GoWatch goroutines.
+	mu      sync.Mutex
 	Errlist map[string][]*nvim.QuickfixError
 }
 
+// SetErrlist sets the errlist of the name to the Errlist.
+func (ctxt *Context) SetErrlist(name string, errlist []*nvim.QuickfixError) {
+	ctxt.mu.Lock()
+	ctxt.Errlist[name] = errlist
+	ctxt.mu.Unlock()
+}
+
+// DeleteErrlist deletes the errlist of the name from the Errlist.
+func (ctxt *Context) DeleteErrlist(name string) {
+	ctxt.mu.Lock()
+	delete(ctxt.Errlist, name)
+	ctxt.mu.Unlock()
+}
+
+// ResetErrlist cleanups the Errlist, and sets the errlist of the name.
+func (ctxt *Context) ResetErrlist(name string, errlist []*nvim.QuickfixError) {
+	ctxt.mu.Lock()
+	ctxt.Errlist = map[string][]*nvim.QuickfixError{name: errlist}
+	ctxt.mu.Unlock()
+}
+
+// CopyErrlist returns the copy of the Errlist, which can be passed to the
+// nvimutil.ErrorList without the lock.
+func (ctxt *Context) CopyErrlist() map[string][]*nvim.QuickfixError {
+	ctxt.mu.Lock()
+	defer ctxt.mu.Unlock()
+
+	errlist := make(map[string][]*nvim.QuickfixError, len(ctxt.Errlist))
+	for name, e := range ctxt.Errlist {
+		errlist[name] = e
+	}
+	return errlist
+}
+
 // Build represents a build tool information.
 type Build struct {
 	// Tool name of build tool
 	Tool string
 	// ProjectRoot package directory full path in the case of go project,
-	// GB_PROJECT_DIR in the case of gb project.
+	// GB_PROJECT_DIR in the case of gb project,
+	// directory of contains the go.mod file in the case of mod project.
 	ProjectRoot string
+	// ModulePath module path declared by the go.mod file in the case of mod project.
+	ModulePath string
+	// VCSRoot root directory of the version control system repository, or
+	// empty if not in any repository.
+	VCSRoot string
+}
+
+// Command returns the command name of the build tool.
+// The mod project uses the go command.
+func (b *Build) Command() string {
+	if b.Tool == "mod" {
+		return "go"
+	}
+	return b.Tool
 }
 
 // NewContext return the Context type with initialize Context.Errlist.
@@ -39,48 +91,117 @@ func NewContext() *Context {
 }
 
 // buildContext return the new build context estimated from the path p directory structure.
-func buildContext(dir string, defaultContext build.Context) (string, string, build.Context) {
+// The estimated Build is cached per directory until the directory layout is changed.
+func buildContext(dir string, defaultContext build.Context) (Build, build.Context) {
 	// copy context
 	buildContext := defaultContext
 
+	key := cacheKey{dir: dir, gopath: defaultContext.GOPATH, go111module: os.Getenv("GO111MODULE")}
+	b := defaultCache.get(key, func(dir string) Build {
+		return estimateBuild(dir, &defaultContext)
+	})
+
+	// Append gb root and vendor path to the goPath lists if gb project.
+	if b.Tool == "gb" {
+		buildContext.GOPATH = b.ProjectRoot + string(filepath.ListSeparator) + filepath.Join(b.ProjectRoot, "vendor")
+	}
+
+	return b, buildContext
+}
+
+// estimateBuild return the Build estimated from the dir directory structure.
+func estimateBuild(dir string, ctxt *build.Context) Build {
+	vcsRoot := pathutil.FindVCSRoot(dir)
+
+	// Check whether the dir is Go modules directory structure.
+	// The nearest go.mod file takes precedence over the GOPATH and gb.
+	if modroot, ok := pathutil.IsMod(dir); ok {
+		modpath, err := pathutil.ModulePath(modroot)
+		if err == nil {
+			return Build{Tool: "mod", ProjectRoot: modroot, ModulePath: modpath, VCSRoot: vcsRoot}
+		}
+		// The broken go.mod file can not resolve the package IDs, so falls
+		// back to the gb or GOPATH.
+		log.Printf("%+v", err)
+	}
+
+	// Check whether the dir is Gb directory structure.
+	if gbpath, ok := pathutil.IsGb(dir); ok {
+		return Build{Tool: "gb", ProjectRoot: gbpath, VCSRoot: vcsRoot}
+	}
+
 	// Default is go context
-	tool := "go"
 	// Assign package directory full path from dir
-	projectRoot, _ := pathutil.PackagePath(dir)
+	projectRoot, _ := pathutil.PackagePathContext(ctxt, dir)
 
-	// Check whether the dir is Gb directory structure.
-	// If ok, append gb root and vendor path to the goPath lists.
-	if gbpath, ok := pathutil.IsGb(filepath.Clean(dir)); ok {
-		tool = "gb"
-		projectRoot = gbpath
-		buildContext.GOPATH = gbpath + string(filepath.ListSeparator) + filepath.Join(gbpath, "vendor")
+	return Build{Tool: "go", ProjectRoot: projectRoot, VCSRoot: vcsRoot}
+}
+
+// BuildContext represents an immutable build context of a request.
+//
+// Each command obtains its own BuildContext from the buffer directory instead
+// of swapping the go/build Default and $GOPATH process-wide, so independent
+// commands in different projects can run concurrently. Do not modify the
+// BuildContext after creation, it may be shared with other goroutines.
+type BuildContext struct {
+	Build
+
+	// Context go/build context of the project.
+	Context build.Context
+	// Env environment variables for subprocesses. The $GOPATH is replaced
+	// with Context.GOPATH.
+	Env []string
+}
+
+// NewBuildContext returns the new BuildContext estimated from the dir directory structure.
+func NewBuildContext(dir string) *BuildContext {
+	defaultMu.Lock()
+	defaultContext := build.Default
+	defaultMu.Unlock()
+
+	b, ctxt := buildContext(dir, defaultContext)
+
+	bctxt := &BuildContext{
+		Build:   b,
+		Context: ctxt,
 	}
+	if bctxt.Build.Tool == "gb" {
+		bctxt.Context.JoinPath = bctxt.GbJoinPath
+	}
+	bctxt.Env = setenv(os.Environ(), "GOPATH", bctxt.Context.GOPATH)
 
-	return tool, projectRoot, buildContext
+	return bctxt
 }
 
-// contextMu Mutex lock for SetContext.
-var contextMu sync.Mutex
+// defaultMu Mutex lock for the go/build Default swapped by WithDefault.
+var defaultMu sync.Mutex
 
-// SetContext sets the go/build Default.GOPATH and $GOPATH to GoPath(p)
-// under a mutex.
-// The returned function restores Default.GOPATH to its original value and
-// unlocks the mutex.
+// WithDefault calls fn with the go/build Default set to the Context of bctxt.
 //
-// This function intended to be used to the go/build Default.
-func (ctx *Context) SetContext(dir string) func() {
-	contextMu.Lock()
+// Some packages such as goimports read the go/build Default directly instead
+// of taking the build.Context, so the Default is replaced only while fn is
+// running, under a mutex. fn must not call WithDefault or NewBuildContext.
+func (bctxt *BuildContext) WithDefault(fn func()) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
 	original := build.Default
+	build.Default = bctxt.Context
+	defer func() { build.Default = original }()
 
-	ctx.Build.Tool, ctx.Build.ProjectRoot, build.Default = buildContext(dir, original)
-	if ctx.Build.Tool == "gb" {
-		build.Default.JoinPath = ctx.Build.GbJoinPath
-	}
-	os.Setenv("GOPATH", build.Default.GOPATH)
+	fn()
+}
 
-	return func() {
-		build.Default = original
-		os.Setenv("GOPATH", build.Default.GOPATH)
-		contextMu.Unlock()
+// setenv returns the copy of env with the key environment variable set to value.
+func setenv(env []string, key, value string) []string {
+	prefix := key + "="
+	newenv := make([]string, 0, len(env)+1)
+	for _, kv := range env {
+		if strings.HasPrefix(kv, prefix) {
+			continue
+		}
+		newenv = append(newenv, kv)
 	}
+
+	return append(newenv, prefix+value)
 }
@@ -0,0 +1,86 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"go/build"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewBuildContext(t *testing.T) {
+	modroot, err := filepath.Abs(filepath.Join("..", "pathutil", "testdata", "mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		dir string
+	}
+	tests := []struct {
+		name string
+		args args
+		want Build
+	}{
+		{
+			name: "mod (module root)",
+			args: args{dir: modroot},
+			want: Build{Tool: "mod", ProjectRoot: modroot, ModulePath: "foo.org/mod"},
+		},
+		{
+			name: "mod (sub package)",
+			args: args{dir: filepath.Join(modroot, "bar", "baz")},
+			want: Build{Tool: "mod", ProjectRoot: modroot, ModulePath: "foo.org/mod"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			original := build.Default
+			got := NewBuildContext(tt.args.dir)
+			// VCSRoot depends on the location of the repository
+			got.Build.VCSRoot = ""
+			if !reflect.DeepEqual(got.Build, tt.want) {
+				t.Errorf("NewBuildContext(%v).Build = %v, want %v", tt.args.dir, got.Build, tt.want)
+			}
+			if build.Default.GOPATH != original.GOPATH {
+				t.Errorf("NewBuildContext(%v) changed build.Default.GOPATH to %v", tt.args.dir, build.Default.GOPATH)
+			}
+		})
+	}
+}
+
+func TestSetenv(t *testing.T) {
+	type args struct {
+		env   []string
+		key   string
+		value string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "replace",
+			args: args{env: []string{"HOME=/home/foo", "GOPATH=/go", "GOPATHX=1"}, key: "GOPATH", value: "/gb:/gb/vendor"},
+			want: []string{"HOME=/home/foo", "GOPATHX=1", "GOPATH=/gb:/gb/vendor"},
+		},
+		{
+			name: "append",
+			args: args{env: []string{"HOME=/home/foo"}, key: "GOPATH", value: "/go"},
+			want: []string{"HOME=/home/foo", "GOPATH=/go"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setenv(tt.args.env, tt.args.key, tt.args.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setenv(%v, %v, %v) = %v, want %v", tt.args.env, tt.args.key, tt.args.value, got, tt.want)
+			}
+		})
+	}
+}
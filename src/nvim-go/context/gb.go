@@ -6,27 +6,27 @@ package context
 
 import (
 	"fmt"
-	"go/build"
 	"path/filepath"
 	"strings"
 )
 
-// GbJoinPath joins the sequence of path fragments into a single path for build.Default.JoinPath.
-func (ctxt *Build) GbJoinPath(elem ...string) string {
+// GbJoinPath joins the sequence of path fragments into a single path for bctxt.Context.JoinPath.
+func (bctxt *BuildContext) GbJoinPath(elem ...string) string {
 	res := filepath.Join(elem...)
+	goos, goarch := bctxt.Context.GOOS, bctxt.Context.GOARCH
 
-	if gbrel, err := filepath.Rel(ctxt.ProjectRoot, res); err == nil {
+	if gbrel, err := filepath.Rel(bctxt.Build.ProjectRoot, res); err == nil {
 		gbrel = filepath.ToSlash(gbrel)
 		gbrel, _ = match(gbrel, "vendor/")
-		if gbrel, ok := match(gbrel, fmt.Sprintf("pkg/%s_%s", build.Default.GOOS, build.Default.GOARCH)); ok {
+		if gbrel, ok := match(gbrel, fmt.Sprintf("pkg/%s_%s", goos, goarch)); ok {
 			gbrel, hasSuffix := match(gbrel, "_")
 
 			if hasSuffix {
 				gbrel = "-" + gbrel
 			}
-			gbrel = fmt.Sprintf("pkg/%s-%s/", build.Default.GOOS, build.Default.GOARCH) + gbrel
+			gbrel = fmt.Sprintf("pkg/%s-%s/", goos, goarch) + gbrel
 			gbrel = filepath.FromSlash(gbrel)
-			res = filepath.Join(ctxt.ProjectRoot, gbrel)
+			res = filepath.Join(bctxt.Build.ProjectRoot, gbrel)
 		}
 	}
 
@@ -0,0 +1,116 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gotest resolves the test function of the cursor position for the
+// go test and dlv test commands.
+package gotest
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Func represents a test, benchmark or example function and the subtests.
+type Func struct {
+	// Name name of the test function.
+	Name string
+	// Subtests names of the nested t.Run subtests ordered by outermost first.
+	Subtests []string
+}
+
+// Enclosing returns the enclosing test function and the subtests of path,
+// which is the result of astutil.PathEnclosingInterval.
+// The subtest which name is not a string literal could not be determined
+// statically, so returns the parent of that subtest.
+func Enclosing(path []ast.Node) (*Func, error) {
+	var (
+		fn       *ast.FuncDecl
+		subtests []string
+	)
+	// path is ordered from the innermost node
+	for _, n := range path {
+		switch x := n.(type) {
+		case *ast.CallExpr:
+			if name, ok := subtestName(x); ok {
+				subtests = append([]string{name}, subtests...)
+			}
+		case *ast.FuncDecl:
+			fn = x
+		}
+	}
+	if fn == nil || fn.Recv != nil {
+		return nil, errors.New("cursor is not in the test function")
+	}
+
+	name := fn.Name.Name
+	if !strings.HasPrefix(name, "Test") && !strings.HasPrefix(name, "Benchmark") && !strings.HasPrefix(name, "Example") {
+		return nil, errors.Errorf("%s is not the test function", name)
+	}
+
+	for i, s := range subtests {
+		if s == "" {
+			subtests = subtests[:i]
+			break
+		}
+	}
+
+	return &Func{Name: name, Subtests: subtests}, nil
+}
+
+// IsBenchmark reports whether the f is a benchmark function.
+func (f *Func) IsBenchmark() bool {
+	return strings.HasPrefix(f.Name, "Benchmark")
+}
+
+// Pattern returns the anchored -run or -bench pattern of f.
+func (f *Func) Pattern() string {
+	pattern := runPattern(f.Name)
+	for _, name := range f.Subtests {
+		pattern += "/" + runPattern(name)
+	}
+	return pattern
+}
+
+// Flags returns the flags which runs only f.
+// The prefix is prepended to the flag names, e.g. "test." for the test binary.
+func (f *Func) Flags(prefix string) []string {
+	if f.IsBenchmark() {
+		return []string{"-" + prefix + "run", "^$", "-" + prefix + "bench", f.Pattern()}
+	}
+	return []string{"-" + prefix + "run", f.Pattern()}
+}
+
+// subtestName returns the subtest name if call is the t.Run or b.Run call.
+// The name is empty if the name is not a string literal.
+func subtestName(call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Run" || len(call.Args) != 2 {
+		return "", false
+	}
+	if _, ok := call.Args[1].(*ast.FuncLit); !ok {
+		return "", false
+	}
+
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", true
+	}
+	name, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", true
+	}
+
+	return name, true
+}
+
+// runPattern returns the anchored pattern of the test name.
+// The testing package rewrites the spaces of subtest name to underscores.
+func runPattern(name string) string {
+	return "^" + regexp.QuoteMeta(strings.Replace(name, " ", "_", -1)) + "$"
+}
@@ -0,0 +1,115 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gotest
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const testFuncSrc = `package foo
+
+import "testing"
+
+func TestFoo(t *testing.T) {
+	_ = "cursor:func"
+	t.Run("sub test", func(t *testing.T) {
+		_ = "cursor:sub"
+		t.Run("nested", func(t *testing.T) {
+			_ = "cursor:nested"
+		})
+	})
+	tests := []struct{ name string }{{name: "a"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = "cursor:table"
+		})
+	}
+}
+
+func BenchmarkFoo(b *testing.B) {
+	_ = "cursor:bench"
+}
+
+func helper() {
+	_ = "cursor:helper"
+}
+`
+
+func TestFunc_Flags(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo_test.go", testFuncSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cursor  string
+		prefix  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "test function",
+			cursor: "cursor:func",
+			want:   []string{"-run", "^TestFoo$"},
+		},
+		{
+			name:   "subtest",
+			cursor: "cursor:sub",
+			want:   []string{"-run", "^TestFoo$/^sub_test$"},
+		},
+		{
+			name:   "nested subtest",
+			cursor: "cursor:nested",
+			want:   []string{"-run", "^TestFoo$/^sub_test$/^nested$"},
+		},
+		{
+			name:   "non literal subtest name",
+			cursor: "cursor:table",
+			want:   []string{"-run", "^TestFoo$"},
+		},
+		{
+			name:   "benchmark",
+			cursor: "cursor:bench",
+			want:   []string{"-run", "^$", "-bench", "^BenchmarkFoo$"},
+		},
+		{
+			name:   "test binary flags",
+			cursor: "cursor:sub",
+			prefix: "test.",
+			want:   []string{"-test.run", "^TestFoo$/^sub_test$"},
+		},
+		{
+			name:    "not test function",
+			cursor:  "cursor:helper",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			offset := fset.File(f.Pos()).Pos(strings.Index(testFuncSrc, tt.cursor))
+			path, _ := astutil.PathEnclosingInterval(f, offset, offset)
+			fn, err := Enclosing(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Enclosing() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := fn.Flags(tt.prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Flags(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
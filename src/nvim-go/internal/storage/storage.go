@@ -0,0 +1,40 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package storage provides the per project data storage of nvim-go.
+package storage
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"nvim-go/context"
+)
+
+// ProjectRoot returns the project root of bctxt which used for the key of
+// the per project data.
+func ProjectRoot(bctxt *context.BuildContext, dir string) string {
+	if bctxt.Build.VCSRoot != "" {
+		return bctxt.Build.VCSRoot
+	}
+	if bctxt.Build.ProjectRoot != "" {
+		return bctxt.Build.ProjectRoot
+	}
+	return dir
+}
+
+// DataPath returns the path of the per project data file which stored under
+// the $XDG_DATA_HOME/nvim-go/<kind>/<hashed project> directory.
+func DataPath(kind, project string, elem ...string) string {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		dataDir = filepath.Join(os.Getenv("HOME"), ".local", "share")
+	}
+
+	path := []string{dataDir, "nvim-go", kind, fmt.Sprintf("%x", sha1.Sum([]byte(project)))}
+
+	return filepath.Join(append(path, elem...)...)
+}
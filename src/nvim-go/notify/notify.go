@@ -0,0 +1,98 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package notify provides a file system notification of the Go package directory.
+package notify
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Exts list of the file extensions which affects the build of the Go package,
+// including the cgo and assembly source files.
+var Exts = []string{".go", ".c", ".h", ".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx", ".m", ".s", ".S", ".swig", ".swigcxx", ".syso"}
+
+// Watcher watches the changes of the Go package source files in the directory.
+// The directory is not watched recursively.
+type Watcher struct {
+	// Dir watching directory.
+	Dir string
+	// Events sends the full path of the changed file.
+	Events chan string
+	// Errors sends the errors of the watching.
+	Errors chan error
+
+	done chan struct{}
+
+	watcher
+}
+
+// NewWatcher starts the watching of the dir directory.
+func NewWatcher(dir string) (*Watcher, error) {
+	w := &Watcher{
+		Dir:    filepath.Clean(dir),
+		Events: make(chan string),
+		Errors: make(chan error),
+		done:   make(chan struct{}),
+	}
+	if err := w.start(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Close stops the watching and closes the Events and Errors channel.
+func (w *Watcher) Close() error {
+	select {
+	case <-w.done:
+		return nil
+	default:
+	}
+	close(w.done)
+
+	return w.stop()
+}
+
+// send sends the name file to the Events channel if the name is the Go package source file.
+// It returns false if the watcher was closed.
+func (w *Watcher) send(name string) bool {
+	if !IsSource(name) {
+		return true
+	}
+	select {
+	case w.Events <- filepath.Join(w.Dir, filepath.Base(name)):
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// sendError sends the err to the Errors channel.
+// It returns false if the watcher was closed.
+func (w *Watcher) sendError(err error) bool {
+	select {
+	case w.Errors <- err:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// IsSource reports whether the name file is the Go package source file.
+// The hidden files such as the editor's swap file are ignored.
+func IsSource(name string) bool {
+	base := filepath.Base(name)
+	if strings.HasPrefix(base, ".") {
+		return false
+	}
+	ext := filepath.Ext(base)
+	for _, e := range Exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package notify
+
+import (
+	"bytes"
+	"os"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// watchMask inotify events which affects the file contents.
+const watchMask = unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO | unix.IN_MOVED_FROM | unix.IN_CREATE | unix.IN_DELETE
+
+// watcher represents a inotify watcher.
+type watcher struct {
+	f *os.File
+}
+
+func (w *Watcher) start() error {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return errors.Wrap(err, "inotify_init1")
+	}
+	if _, err := unix.InotifyAddWatch(fd, w.Dir, watchMask); err != nil {
+		unix.Close(fd)
+		return errors.Wrapf(err, "inotify_add_watch %s", w.Dir)
+	}
+	// os.NewFile returns the pollable File if fd is non-blocking mode,
+	// so the Close unblocks the Read.
+	w.f = os.NewFile(uintptr(fd), "inotify")
+
+	go w.readEvents()
+
+	return nil
+}
+
+func (w *Watcher) stop() error {
+	return w.f.Close()
+}
+
+// readEvents reads the inotify events until the watcher is closed.
+func (w *Watcher) readEvents() {
+	defer close(w.Errors)
+	defer close(w.Events)
+
+	var buf [unix.SizeofInotifyEvent * 4096]byte
+	for {
+		n, err := w.f.Read(buf[:])
+		if err != nil {
+			select {
+			case <-w.done:
+			default:
+				w.sendError(errors.Wrap(err, "read inotify events"))
+			}
+			return
+		}
+
+		var offset int
+		for offset+unix.SizeofInotifyEvent <= n {
+			ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			nameEnd := nameStart + int(ev.Len)
+			if nameEnd > n {
+				break
+			}
+			name := string(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"))
+			offset = nameEnd
+
+			if ev.Mask&unix.IN_Q_OVERFLOW != 0 {
+				if !w.sendError(errors.New("inotify event queue overflowed")) {
+					return
+				}
+				continue
+			}
+			if name == "" {
+				continue
+			}
+			if !w.send(name) {
+				return
+			}
+		}
+	}
+}
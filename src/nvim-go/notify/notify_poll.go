@@ -0,0 +1,93 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package notify
+
+import (
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// pollInterval interval of the polling the directory.
+var pollInterval = 500 * time.Millisecond
+
+// watcher represents a polling watcher for the platform which does not support inotify.
+type watcher struct {
+	modTimes map[string]time.Time
+}
+
+func (w *Watcher) start() error {
+	modTimes, err := w.scan()
+	if err != nil {
+		return err
+	}
+	w.modTimes = modTimes
+
+	go w.poll()
+
+	return nil
+}
+
+func (w *Watcher) stop() error {
+	return nil
+}
+
+// scan returns the modification times of the source files in the directory.
+func (w *Watcher) scan() (map[string]time.Time, error) {
+	fis, err := ioutil.ReadDir(w.Dir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	modTimes := make(map[string]time.Time)
+	for _, fi := range fis {
+		if fi.Mode().IsRegular() && IsSource(fi.Name()) {
+			modTimes[fi.Name()] = fi.ModTime()
+		}
+	}
+	return modTimes, nil
+}
+
+// poll polls the directory until the watcher is closed.
+func (w *Watcher) poll() {
+	defer close(w.Errors)
+	defer close(w.Events)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		modTimes, err := w.scan()
+		if err != nil {
+			if !w.sendError(err) {
+				return
+			}
+			continue
+		}
+		for name, modTime := range modTimes {
+			if old, ok := w.modTimes[name]; !ok || !old.Equal(modTime) {
+				if !w.send(name) {
+					return
+				}
+			}
+		}
+		for name := range w.modTimes {
+			if _, ok := modTimes[name]; !ok {
+				if !w.send(name) {
+					return
+				}
+			}
+		}
+		w.modTimes = modTimes
+	}
+}
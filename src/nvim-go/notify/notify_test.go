@@ -0,0 +1,76 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package notify
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsSource(t *testing.T) {
+	type args struct {
+		name string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{name: "go file", args: args{name: "/foo/bar.go"}, want: true},
+		{name: "cgo file", args: args{name: "bar.c"}, want: true},
+		{name: "assembly file", args: args{name: "bar_amd64.s"}, want: true},
+		{name: "swap file", args: args{name: ".bar.go.swp"}, want: false},
+		{name: "backup file", args: args{name: "bar.go~"}, want: false},
+		{name: "text file", args: args{name: "README.md"}, want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSource(tt.args.name); got != tt.want {
+				t.Errorf("IsSource(%v) = %v, want %v", tt.args.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewWatcher(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo.go"), []byte("package foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(dir, "foo.go")
+	select {
+	case got := <-w.Events:
+		if got != want {
+			t.Errorf("Watcher.Events = %v, want %v", got, want)
+		}
+	case err := <-w.Errors:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the event")
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Watcher.Close() error = %v", err)
+	}
+}
@@ -203,7 +203,9 @@ func SplitPos(pos string, cwd string) (string, int, int) {
 var errRe = regexp.MustCompile(`(?m)^(?:#\s([[:graph:]]+))?(?:[\s\t]+)?([^\s:]+):(\d+)(?::(\d+))?(?::)?\s(.*)`)
 
 // ParseError parses a typical Go tools error messages.
-func ParseError(errs []byte, cwd string, ctxt *context.Build) ([]*nvim.QuickfixError, error) {
+func ParseError(errs []byte, cwd string, bctxt *context.BuildContext) ([]*nvim.QuickfixError, error) {
+	ctxt := &bctxt.Build
+
 	var (
 		// packagePath for the save the error files parent directory.
 		// It will be re-assigned if "# " is in the error message.
@@ -234,8 +236,8 @@ func ParseError(errs []byte, cwd string, ctxt *context.Build) ([]*nvim.QuickfixE
 		case "go":
 			var sep string
 			switch {
-			case pathutil.IsExist(pathutil.JoinGoPath(filename)):
-				filename = pathutil.JoinGoPath(filename)
+			case pathutil.IsExist(pathutil.JoinGoPath(&bctxt.Context, filename)):
+				filename = pathutil.JoinGoPath(&bctxt.Context, filename)
 
 			// filename has not directory path
 			case filepath.Dir(filename) == ".":
@@ -247,8 +249,8 @@ func ParseError(errs []byte, cwd string, ctxt *context.Build) ([]*nvim.QuickfixE
 				filename = strings.TrimPrefix(filename, sep+string(filepath.Separator))
 
 			// filename is like "github.com/foo/bar.go"
-			case strings.HasPrefix(filename, pathutil.TrimGoPath(cwd)):
-				sep = pathutil.TrimGoPath(cwd) + string(filepath.Separator)
+			case strings.HasPrefix(filename, pathutil.TrimGoPath(&bctxt.Context, cwd)):
+				sep = pathutil.TrimGoPath(&bctxt.Context, cwd) + string(filepath.Separator)
 				filename = strings.TrimPrefix(filename, sep)
 			}
 		case "gb":
@@ -256,6 +258,15 @@ func ParseError(errs []byte, cwd string, ctxt *context.Build) ([]*nvim.QuickfixE
 			if !filepath.IsAbs(filename) {
 				filename = filepath.Join(ctxt.ProjectRoot, "src", filename)
 			}
+		case "mod":
+			switch {
+			// filename is like "github.com/foo/bar/baz.go" that joined the "# " package path
+			case ctxt.ModulePath != "" && strings.HasPrefix(filename, ctxt.ModulePath+"/"):
+				filename = filepath.Join(ctxt.ProjectRoot, strings.TrimPrefix(filename, ctxt.ModulePath))
+
+			case !filepath.IsAbs(filename):
+				filename = filepath.Join(cwd, filename)
+			}
 		default:
 			return nil, errors.New("unknown compiler tool")
 		}
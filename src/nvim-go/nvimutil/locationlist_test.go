@@ -259,6 +259,35 @@ package_test.go:36: undeclared name: FindAll`),
 			},
 			wantErr: false,
 		},
+		{
+			name: "mod build",
+			args: args{
+				errors: []byte(`# example.com/foo/bar
+./bar.go:12:2: undefined: baz
+./bar.go:15:9: too many arguments to return`),
+				cwd: filepath.Join(os.Getenv("HOME"), "src", "foo"),
+				ctxt: &context.Build{
+					Tool:        "mod",
+					ProjectRoot: filepath.Join(os.Getenv("HOME"), "src", "foo"),
+					ModulePath:  "example.com/foo",
+				},
+			},
+			want: []*nvim.QuickfixError{
+				&nvim.QuickfixError{
+					FileName: "bar/bar.go",
+					LNum:     12,
+					Col:      2,
+					Text:     "undefined: baz",
+				},
+				&nvim.QuickfixError{
+					FileName: "bar/bar.go",
+					LNum:     15,
+					Col:      9,
+					Text:     "too many arguments to return",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "have_want Go compiler type suggestion",
 			args: args{
@@ -391,12 +420,14 @@ cmd/gaos/versions.go:14: initialization loop:
 		},
 	}
 
-	build.Default.GOPATH = gopath
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got, err := ParseError(tt.args.errors, tt.args.cwd, tt.args.ctxt)
+			bctxt := &context.BuildContext{Build: *tt.args.ctxt, Context: ctxt}
+			got, err := ParseError(tt.args.errors, tt.args.cwd, bctxt)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("%q.\nParseError(%v, %v, %v) error = %v, wantErr %v", tt.name, string(tt.args.errors), tt.args.cwd, tt.args.ctxt, err, tt.wantErr)
 				return
@@ -28,12 +28,15 @@ const (
 	BufOptionFiletype   = "filetype"   // string
 	BufOptionModifiable = "modifiable" // bool
 	BufOptionModified   = "modified"   // bool
+	BufOptionShiftwidth = "shiftwidth" // int
 	BufOptionSwapfile   = "swapfile"   // bool
 
 	// Buffer var
 	BufVarColorcolumn = "colorcolumn" // string
 
 	// Window options
+	WinOptionFoldlevel      = "foldlevel"      // int
+	WinOptionFoldmethod     = "foldmethod"     // string
 	WinOptionList           = "list"           // bool
 	WinOptionNumber         = "number"         // bool
 	WinOptionRelativenumber = "relativenumber" // bool
@@ -60,4 +63,5 @@ const (
 	FiletypeSh         = "sh"
 	FiletypeTerminal   = "terminal"
 	FiletypeGoTerminal = "go-terminal"
+	FiletypeGoTest     = "go-test"
 )
@@ -16,6 +16,8 @@ const pkgNvimSign = "nvim.sign"
 var (
 	BreakpointSymbol         = "\u25cf" // ●  BLACK CIRCLE                         (U+25CF)
 	BreakpointSymbolLarge    = "\u2b24" // ⬤  BLACK LARGE CIRCLE                   (U+2B24)
+	BreakpointCondSymbol     = "\u25d0" // ◐  CIRCLE WITH LEFT HALF BLACK          (U+25D0)
+	BreakpointDisabledSymbol = "\u25cb" // ○  WHITE CIRCLE                         (U+25CB)
 	TracepointSymbol         = "\u25c6" // ◆  BLACK DIAMOND                        (U+25C6)
 	TracepointSymbolMidium   = "\u2b25" // ⬥  BLACK DIAMOND SUIT                   (U+2B25)
 	ProgramCounterSymbol     = "\u25ce" // ◎  BULLSEYE                             (U+25CE)
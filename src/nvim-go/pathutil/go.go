@@ -12,19 +12,19 @@ import (
 )
 
 // parsePackage search the parent directory of dir with the recursive loop.
-func parsePackage(dir string) (*build.Package, error) {
+func parsePackage(ctxt *build.Context, dir string) (*build.Package, error) {
 	dir = filepath.Clean(dir)
 
 	// for save the before(child) package information
 	savePkg := new(build.Package)
 	for {
 		// Raise the error if dir is reaches root("/") or GOPATH or GOROOT
-		if dir == "/" || dir == build.Default.GOPATH || dir == build.Default.GOROOT {
+		if dir == "/" || dir == ctxt.GOPATH || dir == ctxt.GOROOT {
 			return nil, errors.New("couldn't find the package")
 		}
 
 		// Get the current dir package information
-		pkg, err := build.Default.ImportDir(dir, build.ImportMode(0))
+		pkg, err := ctxt.ImportDir(dir, build.ImportMode(0))
 		if err != nil {
 			// Check the exists .go file in the dir
 			if _, ok := err.(*build.NoGoError); ok {
@@ -32,6 +32,8 @@ func parsePackage(dir string) (*build.Package, error) {
 				// Use child(before) directory if NoGoError
 				if savePkg.Dir != "" {
 					return savePkg, nil
+				} else if isModuleRoot(dir) {
+					return nil, errors.New("couldn't find the package")
 				} else {
 					savePkg = pkg
 					dir = filepath.Dir(dir)
@@ -52,18 +54,28 @@ func parsePackage(dir string) (*build.Package, error) {
 			return savePkg, nil
 		}
 
+		// Do not go beyond the module root because the parent directory belongs to other module
+		if isModuleRoot(dir) {
+			return pkg, nil
+		}
+
 		// Save the current package and re-assign dir to parent dir for the next recursive loop
 		savePkg = pkg
 		dir = filepath.Dir(dir)
 	}
 }
 
-// PackagePath returns the *full path* of package directory estimated
-// from the dir directory structure.
+// isModuleRoot reports whether the dir contains the go.mod file.
+func isModuleRoot(dir string) bool {
+	return IsExist(filepath.Join(dir, "go.mod"))
+}
+
+// PackagePathContext returns the *full path* of package directory estimated
+// from the dir directory structure of the ctxt build context.
 // like:
 //  return "/Users/zchee/go/src/github.com/pkg/errors", nil
-func PackagePath(dir string) (string, error) {
-	pkg, err := parsePackage(dir)
+func PackagePathContext(ctxt *build.Context, dir string) (string, error) {
+	pkg, err := parsePackage(ctxt, dir)
 	if err != nil {
 		return "", err
 	}
@@ -71,15 +83,29 @@ func PackagePath(dir string) (string, error) {
 	return pkg.Dir, nil
 }
 
-// PackageID returns the package ID(ImportPath) estimated from the dir
-// directory structure.
+// PackageIDContext returns the package ID(ImportPath) estimated from the dir
+// directory structure of the ctxt build context.
 // like:
 //  return "github.com/pkg/errors", nil
-func PackageID(dir string) (string, error) {
-	pkg, err := parsePackage(dir)
+func PackageIDContext(ctxt *build.Context, dir string) (string, error) {
+	pkg, err := parsePackage(ctxt, dir)
 	if err != nil {
 		return "", err
 	}
 
+	// The ImportPath is local import path such as "_/Users/zchee/src/foo"
+	// if the package is outside of GOPATH, use the module path instead.
+	if root, ok := IsMod(pkg.Dir); ok {
+		modpath, err := ModulePath(root)
+		if err != nil {
+			return "", err
+		}
+		pkgDir, err := filepath.Abs(pkg.Dir)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		return ModulePackageID(root, modpath, pkgDir)
+	}
+
 	return pkg.ImportPath, nil
 }
@@ -11,7 +11,7 @@ import (
 	"testing"
 )
 
-func TestPackagePath(t *testing.T) {
+func TestPackagePathContext(t *testing.T) {
 	var gopath = filepath.Join("testdata", "go")
 
 	type args struct {
@@ -72,25 +72,25 @@ func TestPackagePath(t *testing.T) {
 			wantErr: true,
 		},
 	}
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
 	for _, tt := range tests {
-		build.Default.GOPATH = gopath
-
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got, err := pathutil.PackagePath(tt.args.dir)
+			got, err := pathutil.PackagePathContext(&ctxt, tt.args.dir)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("PackagePath(%v) error = %v, wantErr %v", tt.args.dir, err, tt.wantErr)
+				t.Errorf("PackagePathContext(%v) error = %v, wantErr %v", tt.args.dir, err, tt.wantErr)
 				return
 			}
 			if got != tt.want {
-				t.Errorf("PackagePath(%v) = got: %v, want %v", tt.args.dir, got, tt.want)
+				t.Errorf("PackagePathContext(%v) = got: %v, want %v", tt.args.dir, got, tt.want)
 			}
 		})
 	}
 }
 
-func TestPackageID(t *testing.T) {
+func TestPackageIDContext(t *testing.T) {
 	var gopath = filepath.Join("testdata", "go")
 
 	type args struct {
@@ -151,19 +151,19 @@ func TestPackageID(t *testing.T) {
 			wantErr: true,
 		},
 	}
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
 	for _, tt := range tests {
-		build.Default.GOPATH = gopath
-
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got, err := pathutil.PackageID(tt.args.dir)
+			got, err := pathutil.PackageIDContext(&ctxt, tt.args.dir)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("PackageID(%v) error = %v, wantErr %v", tt.args.dir, err, tt.wantErr)
+				t.Errorf("PackageIDContext(%v) error = %v, wantErr %v", tt.args.dir, err, tt.wantErr)
 				return
 			}
 			if got != tt.want {
-				t.Errorf("PackageID(%v) = %v, want %v", tt.args.dir, got, tt.want)
+				t.Errorf("PackageIDContext(%v) = %v, want %v", tt.args.dir, got, tt.want)
 			}
 		})
 	}
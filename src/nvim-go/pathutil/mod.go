@@ -0,0 +1,143 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IsMod check the current buffer directory whether Go modules directory structure.
+// Return the module root path that contains the go.mod file and boolean.
+func IsMod(dir string) (string, bool) {
+	root, err := FindModuleRoot(dir)
+	if err != nil {
+		return "", false
+	}
+	return root, true
+}
+
+// FindModuleRoot works upwards from path seaching for the go.mod file
+// which identifies the module root.
+func FindModuleRoot(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("module root is blank")
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	start := path
+	for {
+		if IsExist(filepath.Join(path, "go.mod")) {
+			return path, nil
+		}
+		// the filesystem root is also checked
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+	return "", fmt.Errorf(`could not find go.mod in "%s" or its parents`, start)
+}
+
+// ModulePath return the module path declared by the go.mod file in the root directory.
+// like:
+//  return "github.com/pkg/errors", nil
+func ModulePath(root string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	scan := bufio.NewScanner(bytes.NewReader(data))
+	for scan.Scan() {
+		line := strings.TrimSpace(scan.Text())
+		// trim the comment, also the whole line comment
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		// the module keyword must be followed by the space, such as not "modulefoo"
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		modpath := fields[1]
+		if strings.HasPrefix(modpath, `"`) || strings.HasPrefix(modpath, "`") {
+			unquoted, err := strconv.Unquote(modpath)
+			if err != nil {
+				return "", errors.Wrapf(err, "invalid module path %s in %s", modpath, filepath.Join(root, "go.mod"))
+			}
+			modpath = unquoted
+		}
+		if modpath != "" {
+			return modpath, nil
+		}
+	}
+
+	return "", errors.Errorf("could not find module directive in %s", filepath.Join(root, "go.mod"))
+}
+
+// ModulePackageID returns the package ID(ImportPath) of the dir directory
+// that belongs to the module of modpath rooted at the root directory.
+func ModulePackageID(root, modpath, dir string) (string, error) {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("%s is outside of the module root %s", dir, root)
+	}
+
+	return path.Join(modpath, filepath.ToSlash(rel)), nil
+}
+
+// ModulePackageDirs returns the package directories of the module rooted at
+// the root directory. The module is walked on disk, because it may be outside
+// of the GOPATH. The testdata, vendor and nested module directories are skipped.
+func ModulePackageDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		if path != root {
+			name := fi.Name()
+			if name[0] == '.' || name[0] == '_' || name == "testdata" || name == "vendor" {
+				return filepath.SkipDir
+			}
+			if IsExist(filepath.Join(path, "go.mod")) {
+				return filepath.SkipDir
+			}
+		}
+
+		files, err := filepath.Glob(filepath.Join(path, "*.go"))
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return dirs, nil
+}
@@ -0,0 +1,226 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"nvim-go/pathutil"
+)
+
+func TestIsMod(t *testing.T) {
+	var modroot = filepath.Join(testCwd, "testdata", "mod")
+
+	type args struct {
+		dir string
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  string
+		want1 bool
+	}{
+		{
+			name:  "module root",
+			args:  args{dir: modroot},
+			want:  modroot,
+			want1: true,
+		},
+		{
+			name:  "module sub package",
+			args:  args{dir: filepath.Join(modroot, "bar", "baz")},
+			want:  modroot,
+			want1: true,
+		},
+		{
+			name:  "relative path",
+			args:  args{dir: filepath.Join("testdata", "mod", "bar")},
+			want:  modroot,
+			want1: true,
+		},
+		{
+			name:  "go (not module)",
+			args:  args{dir: filepath.Join(testCwd, "testdata", "go", "src", "foo.org", "foo")},
+			want:  "",
+			want1: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := pathutil.IsMod(tt.args.dir)
+			if got != tt.want {
+				t.Errorf("IsMod(%v) got = %v, want %v", tt.args.dir, got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("IsMod(%v) got1 = %v, want %v", tt.args.dir, got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "nvim-go-modpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	type args struct {
+		root string
+	}
+	tests := []struct {
+		name string
+		args args
+		// gomod go.mod file content written to the temporary root if not empty
+		gomod   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "module with comment",
+			args:    args{root: filepath.Join("testdata", "mod")},
+			want:    "foo.org/mod",
+			wantErr: false,
+		},
+		{
+			name:    "no go.mod file",
+			args:    args{root: filepath.Join("testdata", "go")},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:    "quoted module path",
+			gomod:   "module \"foo.org/quoted\"\n",
+			want:    "foo.org/quoted",
+			wantErr: false,
+		},
+		{
+			name:    "back quoted module path",
+			gomod:   "module `foo.org/quoted`\n",
+			want:    "foo.org/quoted",
+			wantErr: false,
+		},
+		{
+			name:    "tab separated",
+			gomod:   "module\tfoo.org/tab\n",
+			want:    "foo.org/tab",
+			wantErr: false,
+		},
+		{
+			name:    "comment lines before module",
+			gomod:   "// module foo.org/comment\n//module foo.org/comment\nmodule foo.org/mod\n",
+			want:    "foo.org/mod",
+			wantErr: false,
+		},
+		{
+			name:    "module prefixed keyword",
+			gomod:   "modulefoo foo.org/bar\nmodule foo.org/mod\n",
+			want:    "foo.org/mod",
+			wantErr: false,
+		},
+		{
+			name:    "no module directive",
+			gomod:   "require foo.org/bar v1.0.0\n",
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:    "invalid quoted module path",
+			gomod:   "module \"foo.org/mod\n",
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		if tt.gomod != "" {
+			tt.args.root = filepath.Join(tmpdir, strconv.Itoa(i))
+			if err := os.MkdirAll(tt.args.root, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(tt.args.root, "go.mod"), []byte(tt.gomod), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pathutil.ModulePath(tt.args.root)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ModulePath(%v) error = %v, wantErr %v", tt.args.root, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ModulePath(%v) = %v, want %v", tt.args.root, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModulePackageID(t *testing.T) {
+	var modroot = filepath.Join(testCwd, "testdata", "mod")
+
+	type args struct {
+		root    string
+		modpath string
+		dir     string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "module root",
+			args:    args{root: modroot, modpath: "foo.org/mod", dir: modroot},
+			want:    "foo.org/mod",
+			wantErr: false,
+		},
+		{
+			name:    "sub package",
+			args:    args{root: modroot, modpath: "foo.org/mod", dir: filepath.Join(modroot, "bar", "baz")},
+			want:    "foo.org/mod/bar/baz",
+			wantErr: false,
+		},
+		{
+			name:    "outside of module",
+			args:    args{root: modroot, modpath: "foo.org/mod", dir: filepath.Dir(modroot)},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pathutil.ModulePackageID(tt.args.root, tt.args.modpath, tt.args.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ModulePackageID(%v, %v, %v) error = %v, wantErr %v", tt.args.root, tt.args.modpath, tt.args.dir, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ModulePackageID(%v, %v, %v) = %v, want %v", tt.args.root, tt.args.modpath, tt.args.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModulePackageDirs(t *testing.T) {
+	var modroot = filepath.Join(testCwd, "testdata", "mod")
+
+	want := []string{modroot, filepath.Join(modroot, "bar", "baz")}
+	got, err := pathutil.ModulePackageDirs(modroot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ModulePackageDirs(%v) = %v, want %v", modroot, got, want)
+	}
+}
@@ -34,11 +34,17 @@ func Chdir(v *nvim.Nvim, dir string) func() {
 	}
 }
 
-// TrimGoPath trims the GOPATH and {bin,pkg,src}, basically for the converts
-// the package ID
-func TrimGoPath(p string) string {
+// TrimGoPath trims the GOPATH of ctxt and {bin,pkg,src}, basically for the
+// converts the package ID. The GOPATH entry which contains p is trimmed, such
+// as the vendor directory of the gb project.
+func TrimGoPath(ctxt *build.Context, p string) string {
 	// Separate trim work for p equal GOPATH
-	p = strings.TrimPrefix(p, build.Default.GOPATH)
+	for _, gopath := range filepath.SplitList(ctxt.GOPATH) {
+		if rest := strings.TrimPrefix(p, gopath); rest != p && (rest == "" || rest[0] == filepath.Separator) {
+			p = rest
+			break
+		}
+	}
 	p = strings.TrimPrefix(p, string(filepath.Separator))
 
 	if len(p) >= 4 {
@@ -51,9 +57,13 @@ func TrimGoPath(p string) string {
 	return p
 }
 
-// JoinGoPath joins the $GOPATH + "src" to p
-func JoinGoPath(p string) string {
-	return filepath.Join(build.Default.GOPATH, "src", p)
+// JoinGoPath joins the first GOPATH entry of ctxt + "src" to p
+func JoinGoPath(ctxt *build.Context, p string) string {
+	var gopath string
+	if list := filepath.SplitList(ctxt.GOPATH); len(list) > 0 {
+		gopath = list[0]
+	}
+	return filepath.Join(gopath, "src", p)
 }
 
 // ShortFilePath return the simply trim cwd into p.
@@ -97,12 +97,13 @@ func TestTrimGoPath(t *testing.T) {
 		},
 	}
 
-	build.Default.GOPATH = testGoPath
+	ctxt := build.Default
+	ctxt.GOPATH = testGoPath
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			if got := pathutil.TrimGoPath(tt.args.p); got != tt.want {
+			if got := pathutil.TrimGoPath(&ctxt, tt.args.p); got != tt.want {
 				t.Errorf("TrimGoPath(%v) = %v, want %v", tt.args.p, got, tt.want)
 			}
 		})
@@ -125,12 +126,13 @@ func TestJoinGoPath(t *testing.T) {
 		},
 	}
 
-	build.Default.GOPATH = testGoPath
+	ctxt := build.Default
+	ctxt.GOPATH = testGoPath
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			if got := pathutil.JoinGoPath(tt.args.p); got != tt.want {
+			if got := pathutil.JoinGoPath(&ctxt, tt.args.p); got != tt.want {
 				t.Errorf("JoinGoPath(%v) = %v, want %v", tt.args.p, got, tt.want)
 			}
 		})
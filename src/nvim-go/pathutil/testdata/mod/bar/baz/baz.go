@@ -0,0 +1,9 @@
+// Copyright 2016 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package baz
+
+func Baz() {
+	print("baz")
+}
@@ -0,0 +1 @@
+module foo.org/mod // nvim-go testdata
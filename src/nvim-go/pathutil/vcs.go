@@ -9,39 +9,24 @@ import (
 	"path/filepath"
 )
 
-var (
-	vcsDirs     = []string{".git", ".svn", ".hg"}
-	foundVCSDir bool
-)
+var vcsDirs = []string{".git", ".svn", ".hg"}
 
-// FindVCSRoot find package root path from arg path
+// FindVCSRoot works upwards from basedir searching for the version control
+// system directory, and returns the directory which contains it.
+// Returns the empty string if basedir is not in any repository.
 func FindVCSRoot(basedir string) string {
-	foundVCSDir = false
-
+	dir := filepath.Clean(basedir)
 	for {
-		filepath.Walk(basedir, findvcsDirWalkFunc)
-		if !foundVCSDir {
-			basedir = filepath.Dir(basedir)
-			continue
+		for _, d := range vcsDirs {
+			if _, err := os.Stat(filepath.Join(dir, d)); err == nil {
+				return dir
+			}
 		}
-		break
-	}
-
-	return filepath.Clean(basedir)
-}
-
-func findvcsDirWalkFunc(path string, fileInfo os.FileInfo, err error) error {
-	if err != nil || fileInfo == nil || fileInfo.IsDir() == false {
-		return nil
-	}
 
-	for _, d := range vcsDirs {
-		_, err := os.Stat(filepath.Join(path, d))
-		if err == nil {
-			foundVCSDir = true
-			break
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
 		}
+		dir = parent
 	}
-
-	return nil
 }
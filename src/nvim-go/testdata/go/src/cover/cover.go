@@ -0,0 +1,16 @@
+package cover
+
+// Abs returns the absolute value of x.
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+type T struct{}
+
+// Name returns the name of t.
+func (t *T) Name() string {
+	return "T"
+}
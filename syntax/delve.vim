@@ -33,7 +33,7 @@ if s:bufname == 'terminal'
   hi def link delveTerminalCommand   Debug
 
 elseif s:bufname == 'context'
-  syn match delveHeadline              /\(Stacktraces\|Local Variables\)/
+  syn match delveHeadline              /\(Stacktraces\|Arguments\|Local Variables\)/
   syn match delveStacksCurrentSymbol   /*/
   syn match delveStacksSymbol          /\(▼\|▶\)/
   syn match delveStacksFunc            /\.\zs\w\+\((\)\@=/ contains=delveStacksIcon
@@ -60,10 +60,42 @@ elseif s:bufname == 'context'
   hi def link delveStacksLenCap        Number
   hi def link delveStacksUnreadable    String
 
-  hi! delveFade1 guibg=#85888d
-  hi! delveFade2 guibg=#5c6066
-  hi! delveFade3 guibg=#343941
-  hi! delveFade4 guibg=#292d34
-  hi! delveFade5 guibg=#1f2227
+elseif s:bufname == 'thread'
+  syn match delveHeadline              /^\(Goroutines\|Threads\)$/
+  syn match delveStacksCurrentSymbol   /^\t\zs\*/
+  syn match delveStacksCurrentSymbol   /^\t\t\zs>/
+  syn match delveThreadsStatus         /\[\(running\|waiting\)\]/
+  syn match delveStacksFunc            /\.\zs\w\+\((\)\@=/
+
+  hi def link delveHeadline            Statement
+  hi def link delveStacksCurrentSymbol Operator
+  hi def link delveThreadsStatus       Comment
+  hi def link delveStacksFunc          Type
+
+elseif s:bufname == 'disassembly'
+  syn match delveHeadline              /^\S\+()$/
+  syn match delveDisasmSource          /^[^\t=* ].*:\d\+:.*$/
+  syn match delveDisasmAddr            /\t\zs0x\x\+\ze\t/
+  syn match delveDisasmBreakpoint      /^ \*/
+  syn match delveDisasmPC              /^=>.*$/
+
+  hi def link delveHeadline            Statement
+  hi def link delveDisasmSource        Comment
+  hi def link delveDisasmAddr          Number
+  hi def link delveDisasmBreakpoint    Operator
+  hi def link delveDisasmPC            delvePCLine
+
+elseif s:bufname == 'watch' || s:bufname == 'eval'
+  syn match delveHeadline              /^\(Watch Expressions\|Evaluation\)$/
+  syn match delveStacksSymbol          /\(▼\|▶\)/
+
+  hi def link delveHeadline            Statement
+  hi def link delveStacksSymbol        Debug
 
 endif
+
+hi! delveFade1 guibg=#85888d
+hi! delveFade2 guibg=#5c6066
+hi! delveFade3 guibg=#343941
+hi! delveFade4 guibg=#292d34
+hi! delveFade5 guibg=#1f2227
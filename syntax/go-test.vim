@@ -0,0 +1,11 @@
+syn match GoTestRun         /^\s*\zsRUN\ze /
+syn match GoTestPass        /^\s*\zsPASS\ze /
+syn match GoTestFail        /^\s*\zsFAIL\ze /
+syn match GoTestSkip        /^\s*\zsSKIP\ze /
+syn match GoTestElapsed     /(\d\+\.\d\+s)$/
+
+hi def link GoTestRun     Function
+hi def link GoTestPass    Statement
+hi def link GoTestFail    Identifier
+hi def link GoTestSkip    Comment
+hi def link GoTestElapsed Number